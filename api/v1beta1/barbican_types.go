@@ -31,6 +31,25 @@ const (
 	// PKCS11PrepHash hash
 	PKCS11PrepHash = "pkcs11prep"
 
+	// SimpleCryptoKEKRewrapHash hash
+	SimpleCryptoKEKRewrapHash = "simplecryptokekrewrap"
+
+	// PKCS11RewrapHash hash
+	PKCS11RewrapHash = "pkcs11rewrap"
+
+	// SoftHSMInitHash hash
+	SoftHSMInitHash = "softhsminit"
+
+	// DBBackupHash hash
+	DBBackupHash = "dbbackup"
+
+	// SecretStoreMigrationHash hash
+	SecretStoreMigrationHash = "secretstoremigration"
+
+	// ConfigRolledOutHash hash of the config-data Secret of the Barbican the
+	// pods of a service rolled out
+	ConfigRolledOutHash = "configrolledout"
+
 	// Container image fall-back defaults
 
 	// BarbicanAPIContainerImage is the fall-back container image for BarbicanAPI
@@ -42,6 +61,10 @@ const (
 	// BarbicanKeystoneListenerContainerImage is the fall-back container image for BarbicanAPI
 	BarbicanKeystoneListenerContainerImage = "quay.io/podified-antelope-centos9/openstack-barbican-keystone-listener:current-podified"
 
+	// BarbicanDBClientContainerImage is the fall-back container image of the
+	// database dumps, the Barbican images do not ship mysqldump
+	BarbicanDBClientContainerImage = "quay.io/podified-antelope-centos9/openstack-mariadb:current-podified"
+
 	// APITimeout is the default Barbican API timeout
 	APITimeout = 90
 )
@@ -101,11 +124,18 @@ type BarbicanSpecBase struct {
 	CustomServiceConfig string `json:"customServiceConfig,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	// ConfigOverwrite - interface to overwrite default config files like e.g. logging.conf or policy.json.
-	// But can also be used to add additional files. Those get added to the service config dir in /etc/<service> .
-	// TODO(dmendiza): -> implement
+	// ConfigOverwrite - interface to overwrite default config files like e.g. logging.conf or policy.yaml.
+	// But can also be used to add additional files. Those get added to the service config dir in /etc/<service>
+	// of all the services, the defaultConfigOverwrite of a service takes precedence. Files generated by the
+	// operator, like 00-default.conf or httpd.conf, can not be overwritten.
 	DefaultConfigOverwrite map[string]string `json:"defaultConfigOverwrite,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// Logging - log level and format of all the Barbican services and jobs, the logging
+	// of a service can override it. Rendered as oslo.log options and as the logging.conf
+	// of the services.
+	Logging *BarbicanLoggingTemplate `json:"logging,omitempty"`
+
 	// +kubebuilder:validation:Required
 	// BarbicanAPIInternal - Spec definition for the internal and admin API service of this Barbican deployment
 
@@ -115,6 +145,25 @@ type BarbicanSpecBase struct {
 	// Barbican API timeout
 	APITimeout int `json:"apiTimeout"`
 
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// GenerateSimpleCryptoKEK - create the SimpleCryptoBackendSecret with a random KEK under the
+	// SimpleCryptoKEK selector when it does not exist. Meant for dev and CI environments, the
+	// generated Secret is kept when the Barbican CR is deleted.
+	GenerateSimpleCryptoKEK bool `json:"generateSimpleCryptoKEK"`
+
+	// +kubebuilder:validation:Optional
+	// SecretStoreMigration - re-encrypts the secrets of the source secret store with the
+	// target one. The source store stays the global default until the migration completed,
+	// globalDefaultSecretStore, which must be the target store, is only rendered afterwards.
+	SecretStoreMigration *BarbicanSecretStoreMigrationTemplate `json:"secretStoreMigration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ProjectSecretStores - preferred secret store of Keystone projects, keyed by project ID.
+	// The preferences are set through the Barbican API with a token of the service user
+	// scoped to the project, so the service user needs the admin role on these projects.
+	ProjectSecretStores map[string]SecretStore `json:"projectSecretStores,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// Auth - Parameters related to authentication for all Barbican services
@@ -124,6 +173,84 @@ type BarbicanSpecBase struct {
 	// TopologyRef to apply the Topology defined by the associated CR referenced
 	// by name
 	TopologyRef *topologyv1.TopoRef `json:"topologyRef,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// HaltUpdateOnFailure - stop a minor update in the phase whose services fail to roll
+	// out, instead of moving on to the next phase. The update resumes once the services
+	// of the phase are ready or the container images change.
+	HaltUpdateOnFailure bool `json:"haltUpdateOnFailure"`
+
+	// +kubebuilder:validation:Optional
+	// DBPurge - removes the soft-deleted secrets, containers and orders from the database
+	// on a schedule, with barbican-manage db clean run by a CronJob
+	DBPurge *BarbicanDBPurgeTemplate `json:"dbPurge,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DBBackup - dumps the database with mysqldump before the db-sync runs for a new
+	// API image, the db-sync waits for the dump to complete. A failed dump is retried
+	// once its Job is deleted.
+	DBBackup *BarbicanDBBackupTemplate `json:"dbBackup,omitempty"`
+}
+
+// BarbicanDBBackupTemplate - where the database dumps are written and how many are kept
+type BarbicanDBBackupTemplate struct {
+	// +kubebuilder:validation:Optional
+	// ContainerImage - image providing mysqldump, and curl for an object store. Defaults
+	// to the MariaDB image of the operator.
+	ContainerImage string `json:"containerImage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PersistentVolumeClaim - name of the PersistentVolumeClaim the dumps are written to
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ObjectStoreSecret - name of the Secret with the endpoint, bucket, region, accessKeyID
+	// and secretAccessKey of the S3 compatible object store the dumps are uploaded to
+	ObjectStoreSecret string `json:"objectStoreSecret,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// Retention - number of dumps kept, the older ones are pruned after a dump
+	Retention int `json:"retention"`
+}
+
+// BarbicanDBPurgeTemplate - schedule and options of the database cleanup
+type BarbicanDBPurgeTemplate struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="1 0 * * *"
+	// Schedule - cron schedule of the cleanup
+	Schedule string `json:"schedule"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=1
+	// Age - in days, the soft-deleted entries older than this are removed
+	Age int `json:"age"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// CleanUnassociatedProjects - also remove the projects without secrets, containers or orders
+	CleanUnassociatedProjects bool `json:"cleanUnassociatedProjects"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// SoftDeleteExpiredSecrets - soft-delete the expired secrets before the cleanup
+	SoftDeleteExpiredSecrets bool `json:"softDeleteExpiredSecrets"`
+}
+
+// BarbicanSecretStoreMigrationTemplate - names the secret stores to migrate the secrets between
+type BarbicanSecretStoreMigrationTemplate struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=simple_crypto;pkcs11
+	// Source - secret store the secrets are currently stored in
+	Source SecretStore `json:"source"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=simple_crypto;pkcs11
+	// Target - secret store the secrets are moved to
+	Target SecretStore `json:"target"`
 }
 
 // BarbicanStatus defines the observed state of Barbican
@@ -160,6 +287,258 @@ type BarbicanStatus struct {
 	// then the controller has not processed the latest changes injected by
 	// the opentack-operator in the top-level CR (e.g. the ContainerImage)
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SimpleCryptoKEKGenerated - the Simple Crypto KEK was generated by the operator
+	// rather than supplied by the user
+	SimpleCryptoKEKGenerated bool `json:"simpleCryptoKEKGenerated,omitempty"`
+
+	// SimpleCryptoKEKGenerationTime - time the Simple Crypto KEK was generated
+	SimpleCryptoKEKGenerationTime *metav1.Time `json:"simpleCryptoKEKGenerationTime,omitempty"`
+
+	// SimpleCryptoKEKRotation - status of the Simple Crypto KEK rotation
+	SimpleCryptoKEKRotation *SimpleCryptoKEKRotationStatus `json:"simpleCryptoKEKRotation,omitempty"`
+
+	// PKCS11KeyRotation - status of the PKCS11 MKEK and HMAC key rotation
+	PKCS11KeyRotation *PKCS11KeyRotationStatus `json:"pkcs11KeyRotation,omitempty"`
+
+	// SecretStoreMigration - status of the last secret store migration
+	SecretStoreMigration *SecretStoreMigrationStatus `json:"secretStoreMigration,omitempty"`
+
+	// GlobalDefaultSecretStore - secret store rendered as the global default
+	GlobalDefaultSecretStore SecretStore `json:"globalDefaultSecretStore,omitempty"`
+
+	// ProjectSecretStores - preferred secret store of the projects in ProjectSecretStores,
+	// as reported by the Barbican API
+	ProjectSecretStores map[string]ProjectSecretStoreStatus `json:"projectSecretStores,omitempty"`
+
+	// Update - status of the last minor update of the container images
+	Update *BarbicanUpdateStatus `json:"update,omitempty"`
+
+	// DatabaseSchema - revision of the database schema after the last db-sync
+	DatabaseSchema *BarbicanDatabaseSchemaStatus `json:"databaseSchema,omitempty"`
+
+	// DBBackup - status of the last database dump taken before a db-sync
+	DBBackup *BarbicanDBBackupStatus `json:"dbBackup,omitempty"`
+}
+
+// BarbicanDBBackupState - state of a database dump
+type BarbicanDBBackupState string
+
+const (
+	// BarbicanDBBackupRunning - the backup Job is running
+	BarbicanDBBackupRunning BarbicanDBBackupState = "Running"
+	// BarbicanDBBackupCompleted - the backup Job succeeded
+	BarbicanDBBackupCompleted BarbicanDBBackupState = "Completed"
+	// BarbicanDBBackupFailed - the backup Job failed
+	BarbicanDBBackupFailed BarbicanDBBackupState = "Failed"
+)
+
+// BarbicanDBBackupStatus defines the observed state of a database dump
+type BarbicanDBBackupStatus struct {
+	// Dump - name of the dump in the PersistentVolumeClaim or the bucket
+	Dump string `json:"dump,omitempty"`
+
+	// Image - API image whose db-sync the dump was taken before
+	Image string `json:"image,omitempty"`
+
+	// State - state of the dump
+	State BarbicanDBBackupState `json:"state,omitempty"`
+
+	// StartTime - time the dump started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime - time the dump completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// BarbicanDatabaseSchemaStatus defines the observed revision of the database schema
+type BarbicanDatabaseSchemaStatus struct {
+	// Revision - alembic revision the database is at
+	Revision string `json:"revision,omitempty"`
+
+	// Image - container image of the db-sync that upgraded the database to the revision
+	Image string `json:"image,omitempty"`
+
+	// SyncTime - time the db-sync completed
+	SyncTime *metav1.Time `json:"syncTime,omitempty"`
+}
+
+// BarbicanUpdatePhase - phase of a minor update
+type BarbicanUpdatePhase string
+
+const (
+	// BarbicanUpdateDBSync - the db-sync Job runs with the new API image
+	BarbicanUpdateDBSync BarbicanUpdatePhase = "DBSync"
+	// BarbicanUpdateKeystoneListenerAndWorker - the keystone listener and the worker roll out
+	BarbicanUpdateKeystoneListenerAndWorker BarbicanUpdatePhase = "KeystoneListenerAndWorker"
+	// BarbicanUpdateAPI - the API rolls out
+	BarbicanUpdateAPI BarbicanUpdatePhase = "API"
+	// BarbicanUpdateCompleted - all the services run the target images
+	BarbicanUpdateCompleted BarbicanUpdatePhase = "Completed"
+)
+
+// BarbicanUpdateImages - container images of the Barbican services
+type BarbicanUpdateImages struct {
+	// API - container image of the BarbicanAPI, also used by the db-sync Job
+	API string `json:"api,omitempty"`
+
+	// Worker - container image of the BarbicanWorker
+	Worker string `json:"worker,omitempty"`
+
+	// KeystoneListener - container image of the BarbicanKeystoneListener
+	KeystoneListener string `json:"keystoneListener,omitempty"`
+}
+
+// BarbicanUpdateStatus defines the observed state of a minor update
+type BarbicanUpdateStatus struct {
+	// Phase - current phase of the update
+	Phase BarbicanUpdatePhase `json:"phase,omitempty"`
+
+	// TargetImages - container images the services are updated to
+	TargetImages BarbicanUpdateImages `json:"targetImages,omitempty"`
+
+	// PreviousImages - container images the services ran when the update started
+	PreviousImages BarbicanUpdateImages `json:"previousImages,omitempty"`
+
+	// StartTime - time the update started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime - time the update completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message - failure of the last phase that failed to roll out, if any
+	Message string `json:"message,omitempty"`
+}
+
+// ProjectSecretStoreStatus defines the observed preferred secret store of a project
+type ProjectSecretStoreStatus struct {
+	// Preferred - preferred secret store of the project in the Barbican API, empty when
+	// the project uses the global default
+	Preferred SecretStore `json:"preferred,omitempty"`
+
+	// InSync - whether the preferred secret store matches the spec
+	InSync bool `json:"inSync"`
+
+	// LastDriftTime - last time the preferred secret store was found to differ from the spec
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
+
+	// LastSyncTime - last time the preferred secret store was checked
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Error - error of the last check, if any
+	Error string `json:"error,omitempty"`
+}
+
+// SecretStoreMigrationState - state of a secret store migration
+type SecretStoreMigrationState string
+
+const (
+	// SecretStoreMigrationPending - waiting for the services before starting the migration Job
+	SecretStoreMigrationPending SecretStoreMigrationState = "Pending"
+	// SecretStoreMigrationRunning - the migration Job is running
+	SecretStoreMigrationRunning SecretStoreMigrationState = "Running"
+	// SecretStoreMigrationCompleted - the migration Job succeeded
+	SecretStoreMigrationCompleted SecretStoreMigrationState = "Completed"
+	// SecretStoreMigrationFailed - the migration Job failed
+	SecretStoreMigrationFailed SecretStoreMigrationState = "Failed"
+)
+
+// SecretStoreMigrationStatus defines the observed state of a secret store migration
+type SecretStoreMigrationStatus struct {
+	// Source - secret store the secrets are migrated from
+	Source SecretStore `json:"source,omitempty"`
+
+	// Target - secret store the secrets are migrated to
+	Target SecretStore `json:"target,omitempty"`
+
+	// State - state of the migration
+	State SecretStoreMigrationState `json:"state,omitempty"`
+
+	// StartTime - time the migration was requested, or retried
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime - time the migration completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Secrets - number of secrets the migration Job found in the source store
+	Secrets int32 `json:"secrets,omitempty"`
+
+	// MigratedSecrets - number of secrets the migration Job moved to the target store
+	MigratedSecrets int32 `json:"migratedSecrets,omitempty"`
+
+	// FailedSecrets - number of secrets the migration Job could not move, a failed
+	// migration is retried once its Job is deleted
+	FailedSecrets int32 `json:"failedSecrets,omitempty"`
+}
+
+// SimpleCryptoKEKRotationState - state of a Simple Crypto KEK rotation
+type SimpleCryptoKEKRotationState string
+
+const (
+	// SimpleCryptoKEKRotationPending - waiting for the services to render the new active KEK
+	SimpleCryptoKEKRotationPending SimpleCryptoKEKRotationState = "Pending"
+	// SimpleCryptoKEKRotationRunning - the rewrap Job is running
+	SimpleCryptoKEKRotationRunning SimpleCryptoKEKRotationState = "Running"
+	// SimpleCryptoKEKRotationCompleted - the rewrap Job succeeded
+	SimpleCryptoKEKRotationCompleted SimpleCryptoKEKRotationState = "Completed"
+	// SimpleCryptoKEKRotationFailed - the rewrap Job failed
+	SimpleCryptoKEKRotationFailed SimpleCryptoKEKRotationState = "Failed"
+)
+
+// SimpleCryptoKEKRotationStatus defines the observed state of the Simple Crypto KEK rotation
+type SimpleCryptoKEKRotationStatus struct {
+	// ActiveKEK - field of the SimpleCryptoBackendSecret holding the KEK the
+	// project KEKs are wrapped with
+	ActiveKEK string `json:"activeKEK,omitempty"`
+
+	// PendingKEK - field of the SimpleCryptoBackendSecret holding the KEK a
+	// rotation in progress is moving to
+	PendingKEK string `json:"pendingKEK,omitempty"`
+
+	// RetiredKEKs - fields of the SimpleCryptoBackendSecret holding KEKs that
+	// have been rotated out and are no longer rendered in the service config
+	RetiredKEKs []string `json:"retiredKEKs,omitempty"`
+
+	// State - state of the last rotation
+	State SimpleCryptoKEKRotationState `json:"state,omitempty"`
+
+	// LastRotationTime - time the last rotation completed
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// PKCS11KeyRotationState - state of a PKCS11 MKEK and HMAC key rotation
+type PKCS11KeyRotationState string
+
+const (
+	// PKCS11KeyRotationPending - waiting for the services to render the new labels
+	PKCS11KeyRotationPending PKCS11KeyRotationState = "Pending"
+	// PKCS11KeyRotationRunning - the rewrap Job is running
+	PKCS11KeyRotationRunning PKCS11KeyRotationState = "Running"
+	// PKCS11KeyRotationCompleted - the rewrap Job succeeded
+	PKCS11KeyRotationCompleted PKCS11KeyRotationState = "Completed"
+	// PKCS11KeyRotationFailed - the rewrap Job failed
+	PKCS11KeyRotationFailed PKCS11KeyRotationState = "Failed"
+)
+
+// PKCS11KeyRotationStatus defines the observed state of the PKCS11 MKEK and HMAC key rotation
+type PKCS11KeyRotationStatus struct {
+	// MKEKLabel - label of the MKEK the project KEKs are wrapped with
+	MKEKLabel string `json:"mkekLabel,omitempty"`
+
+	// HMACLabel - label of the HMAC key the project KEKs are signed with
+	HMACLabel string `json:"hmacLabel,omitempty"`
+
+	// PendingMKEKLabel - label of the MKEK a rotation in progress is moving to
+	PendingMKEKLabel string `json:"pendingMKEKLabel,omitempty"`
+
+	// PendingHMACLabel - label of the HMAC key a rotation in progress is moving to
+	PendingHMACLabel string `json:"pendingHMACLabel,omitempty"`
+
+	// State - state of the last rotation
+	State PKCS11KeyRotationState `json:"state,omitempty"`
+
+	// LastRotationTime - time the last rotation completed
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -216,6 +595,7 @@ func SetupDefaults() {
 		APIContainerImageURL:              util.GetEnvVar("RELATED_IMAGE_BARBICAN_API_IMAGE_URL_DEFAULT", BarbicanAPIContainerImage),
 		WorkerContainerImageURL:           util.GetEnvVar("RELATED_IMAGE_BARBICAN_WORKER_IMAGE_URL_DEFAULT", BarbicanWorkerContainerImage),
 		KeystoneListenerContainerImageURL: util.GetEnvVar("RELATED_IMAGE_BARBICAN_KEYSTONE_LISTENER_IMAGE_URL_DEFAULT", BarbicanKeystoneListenerContainerImage),
+		DBClientContainerImageURL:         util.GetEnvVar("RELATED_IMAGE_BARBICAN_DB_CLIENT_IMAGE_URL_DEFAULT", BarbicanDBClientContainerImage),
 		BarbicanAPITimeout:                APITimeout,
 	}
 
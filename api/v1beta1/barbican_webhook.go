@@ -24,14 +24,25 @@ package v1beta1
 
 import (
 	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
 	"slices"
+	"strings"
 
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	common_webhook "github.com/openstack-k8s-operators/lib-common/modules/common/webhook"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -43,6 +54,7 @@ type BarbicanDefaults struct {
 	APIContainerImageURL              string
 	WorkerContainerImageURL           string
 	KeystoneListenerContainerImageURL string
+	DBClientContainerImageURL         string
 	BarbicanAPITimeout                int
 }
 
@@ -57,6 +69,16 @@ func SetupBarbicanDefaults(defaults BarbicanDefaults) {
 	barbicanlog.Info("Barbican defaults initialized", "defaults", defaults)
 }
 
+// GetDBClientContainerImage - returns the image the database dumps and
+// restores default to, as set by RELATED_IMAGE_BARBICAN_DB_CLIENT_IMAGE_URL_DEFAULT.
+// The objects the webhook did not default fall back to it.
+func GetDBClientContainerImage() string {
+	if barbicanDefaults.DBClientContainerImageURL == "" {
+		return BarbicanDBClientContainerImage
+	}
+	return barbicanDefaults.DBClientContainerImageURL
+}
+
 var _ webhook.Defaulter = &Barbican{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
@@ -89,6 +111,11 @@ func (spec *BarbicanSpecBase) Default() {
 	if spec.MessagingBus.Cluster == "" {
 		spec.MessagingBus.Cluster = "rabbitmq"
 	}
+
+	// the images of the services do not ship mysqldump
+	if spec.DBBackup != nil && spec.DBBackup.ContainerImage == "" {
+		spec.DBBackup.ContainerImage = barbicanDefaults.DBClientContainerImageURL
+	}
 }
 
 // Default - set defaults for this BarbicanSpecBase. NOTE: this version is used by the OpenStackControlplane webhook
@@ -136,6 +163,45 @@ func (spec *BarbicanSpec) ValidateCreate(basePath *field.Path, namespace string)
 	// pkcs11 verifications
 	spec.ValidatePKCS11(basePath, &allErrs)
 
+	// kmip verifications
+	spec.ValidateKMIP(basePath, &allErrs)
+
+	// vault verifications
+	spec.ValidateVault(basePath, &allErrs)
+
+	// simple crypto verifications
+	spec.ValidateSimpleCryptoActiveKEK(basePath, &allErrs)
+
+	// secret store migration verifications
+	spec.ValidateSecretStoreMigration(basePath, &allErrs)
+
+	// project secret store verifications
+	spec.ValidateProjectSecretStores(basePath, &allErrs)
+
+	// default config overwrite verifications
+	allErrs = append(allErrs, spec.ValidateDefaultConfigOverwrite(basePath)...)
+
+	// logging verifications
+	allErrs = append(allErrs, spec.ValidateLogging(basePath)...)
+
+	// autoscaling verifications
+	allErrs = append(allErrs, spec.ValidateAutoscaling(basePath)...)
+
+	// pod disruption budget verifications
+	allErrs = append(allErrs, spec.ValidatePodDisruptionBudget(basePath)...)
+
+	// rollout strategy verifications
+	allErrs = append(allErrs, spec.ValidateRolloutStrategy(basePath)...)
+
+	// db backup verifications
+	allErrs = append(allErrs, spec.ValidateDBBackup(basePath)...)
+
+	// extra mounts verifications
+	allErrs = append(allErrs, spec.ValidateExtraMounts(basePath)...)
+
+	// database verifications
+	allErrs = append(allErrs, spec.ValidateDatabase(basePath)...)
+
 	allErrs = append(allErrs, spec.ValidateBarbicanTopology(basePath, namespace)...)
 
 	return allWarns, allErrs
@@ -148,7 +214,192 @@ func (spec *BarbicanSpec) ValidatePKCS11(basePath *field.Path, allErrs *field.Er
 			*allErrs = append(*allErrs, field.Required(basePath.Child("PKCS11"),
 				"PKCS11 specification is missing, PKCS11 is required when pkcs11 is an enabled SecretStore"),
 			)
+			return
 		}
+		if (spec.PKCS11.MKEKLabel == "") != (spec.PKCS11.HMACLabel == "") {
+			*allErrs = append(*allErrs, field.Required(basePath.Child("PKCS11"),
+				"mkekLabel and hmacLabel must be set together"),
+			)
+		}
+		spec.PKCS11.validate(basePath.Child("PKCS11"), allErrs)
+	}
+}
+
+// ValidateSecretStoreMigration validates that the secrets are migrated between two enabled
+// secret stores, to the store set as the global default
+func (spec *BarbicanSpec) ValidateSecretStoreMigration(basePath *field.Path, allErrs *field.ErrorList) {
+	migration := spec.SecretStoreMigration
+	if migration == nil {
+		return
+	}
+	path := basePath.Child("secretStoreMigration")
+	if migration.Source == migration.Target {
+		*allErrs = append(*allErrs, field.Invalid(path.Child("target"), migration.Target,
+			"target must differ from source"))
+	}
+	for _, store := range []struct {
+		name  string
+		value SecretStore
+	}{
+		{"source", migration.Source},
+		{"target", migration.Target},
+	} {
+		if !slices.Contains(spec.EnabledSecretStores, store.value) {
+			*allErrs = append(*allErrs, field.Invalid(path.Child(store.name), store.value,
+				"secret store must be in enabledSecretStores"))
+		}
+	}
+	if spec.GlobalDefaultSecretStore != migration.Target {
+		*allErrs = append(*allErrs, field.Invalid(basePath.Child("globalDefaultSecretStore"),
+			spec.GlobalDefaultSecretStore,
+			"globalDefaultSecretStore must be the target of the secret store migration"))
+	}
+}
+
+// ValidateProjectSecretStores validates that the projects prefer enabled secret stores
+func (spec *BarbicanSpec) ValidateProjectSecretStores(basePath *field.Path, allErrs *field.ErrorList) {
+	if len(spec.ProjectSecretStores) == 0 {
+		return
+	}
+	path := basePath.Child("projectSecretStores")
+	if len(spec.EnabledSecretStores) == 0 {
+		*allErrs = append(*allErrs, field.Required(basePath.Child("enabledSecretStores"),
+			"enabledSecretStores is required by projectSecretStores"))
+		return
+	}
+	projects := slices.Sorted(maps.Keys(spec.ProjectSecretStores))
+	for _, project := range projects {
+		store := spec.ProjectSecretStores[project]
+		if project == "" {
+			*allErrs = append(*allErrs, field.Invalid(path, project, "project ID must not be empty"))
+			continue
+		}
+		if !slices.Contains(spec.EnabledSecretStores, store) {
+			*allErrs = append(*allErrs, field.Invalid(path.Key(project), store,
+				"secret store must be in enabledSecretStores"))
+		}
+	}
+}
+
+var (
+	// pkcs11LabelRegexp matches the labels that can be safely rendered in the
+	// config and the prep script
+	pkcs11LabelRegexp = regexp.MustCompile(`^[A-Za-z0-9 _.:-]+$`)
+	// pkcs11MechanismRegexp matches PKCS11 mechanisms, including vendor defined ones
+	pkcs11MechanismRegexp = regexp.MustCompile(`^(VENDOR_[A-Z0-9]+_)?CKM_[A-Z0-9_]+$`)
+	// pkcs11KeyTypeRegexp matches PKCS11 key types
+	pkcs11KeyTypeRegexp = regexp.MustCompile(`^CKK_[A-Z0-9_]+$`)
+	// pkcs11EncryptionMechanisms are the encryption mechanisms supported by the p11_crypto plugin
+	pkcs11EncryptionMechanisms = []string{"CKM_AES_CBC", "CKM_AES_GCM", "VENDOR_SAFENET_CKM_AES_GCM"}
+	// pkcs11MKEKLengths are the valid AES key lengths for the MKEK
+	pkcs11MKEKLengths = []int{16, 24, 32}
+)
+
+// validate checks the typed p11_crypto_plugin settings
+func (p *BarbicanPKCS11Template) validate(path *field.Path, allErrs *field.ErrorList) {
+	if p.Vendor != HSMVendorSoftHSM && p.ClientDataSecret == "" {
+		*allErrs = append(*allErrs, field.Required(path.Child("clientDataSecret"),
+			"clientDataSecret is required unless vendor is softhsm"))
+	}
+	if p.Vendor == HSMVendorSoftHSM {
+		if p.MKEKLabel == "" {
+			*allErrs = append(*allErrs, field.Required(path.Child("mkekLabel"),
+				"mkekLabel is required by the softhsm vendor"))
+		}
+		if p.SoftHSM != nil && p.SoftHSM.StorageRequest != "" {
+			if _, err := resource.ParseQuantity(p.SoftHSM.StorageRequest); err != nil {
+				*allErrs = append(*allErrs, field.Invalid(path.Child("softHSM", "storageRequest"),
+					p.SoftHSM.StorageRequest, err.Error()))
+			}
+		}
+	}
+	if (p.Vendor == HSMVendorLuna || p.Vendor == HSMVendorProteccio) && len(p.Servers) == 0 {
+		*allErrs = append(*allErrs, field.Required(path.Child("servers"),
+			fmt.Sprintf("servers are required by the %s vendor", p.Vendor)))
+	}
+	for i, server := range p.Servers {
+		if net.ParseIP(server) == nil && len(validation.IsDNS1123Subdomain(server)) != 0 {
+			*allErrs = append(*allErrs, field.Invalid(path.Child("servers").Index(i),
+				server, "must be a hostname or an IP address"))
+		}
+	}
+	for _, l := range []struct{ name, value string }{
+		{"mkekLabel", p.MKEKLabel},
+		{"hmacLabel", p.HMACLabel},
+		{"tokenLabel", p.TokenLabel},
+	} {
+		if l.value != "" && !pkcs11LabelRegexp.MatchString(l.value) {
+			*allErrs = append(*allErrs, field.Invalid(path.Child(l.name),
+				l.value, "may only contain letters, digits, spaces and the characters _.:-"))
+		}
+	}
+	if p.LibraryPath != "" && !filepath.IsAbs(p.LibraryPath) {
+		*allErrs = append(*allErrs, field.Invalid(path.Child("libraryPath"),
+			p.LibraryPath, "must be an absolute path"))
+	}
+	if p.MKEKLength != 0 && !slices.Contains(pkcs11MKEKLengths, p.MKEKLength) {
+		*allErrs = append(*allErrs, field.NotSupported(path.Child("mkekLength"),
+			p.MKEKLength, []string{"16", "24", "32"}))
+	}
+	if p.EncryptionMechanism != "" && !slices.Contains(pkcs11EncryptionMechanisms, p.EncryptionMechanism) {
+		*allErrs = append(*allErrs, field.NotSupported(path.Child("encryptionMechanism"),
+			p.EncryptionMechanism, pkcs11EncryptionMechanisms))
+	}
+	if p.HMACKeyType != "" && !pkcs11KeyTypeRegexp.MatchString(p.HMACKeyType) {
+		*allErrs = append(*allErrs, field.Invalid(path.Child("hmacKeyType"),
+			p.HMACKeyType, "must be a PKCS11 key type such as CKK_GENERIC_SECRET"))
+	}
+	for _, m := range []struct{ name, value string }{
+		{"hmacKeygenMechanism", p.HMACKeygenMechanism},
+		{"hmacMechanism", p.HMACMechanism},
+		{"keyWrapMechanism", p.KeyWrapMechanism},
+	} {
+		if m.value != "" && !pkcs11MechanismRegexp.MatchString(m.value) {
+			*allErrs = append(*allErrs, field.Invalid(path.Child(m.name),
+				m.value, "must be a PKCS11 mechanism such as CKM_SHA256_HMAC"))
+		}
+	}
+}
+
+// ValidateKMIP validates that KMIP configuration is provided when KMIP is an enabled secret store
+func (spec *BarbicanSpec) ValidateKMIP(basePath *field.Path, allErrs *field.ErrorList) {
+	if slices.Contains(spec.EnabledSecretStores, SecretStoreKMIP) {
+		if spec.KMIP == nil {
+			*allErrs = append(*allErrs, field.Required(basePath.Child("kmip"),
+				"KMIP specification is missing, KMIP is required when kmip is an enabled SecretStore"),
+			)
+		}
+	}
+}
+
+// ValidateVault validates that Vault configuration is provided when Vault is an enabled secret store
+func (spec *BarbicanSpec) ValidateVault(basePath *field.Path, allErrs *field.ErrorList) {
+	if slices.Contains(spec.EnabledSecretStores, SecretStoreVault) {
+		if spec.Vault == nil {
+			*allErrs = append(*allErrs, field.Required(basePath.Child("vault"),
+				"Vault specification is missing, Vault is required when vault is an enabled SecretStore"),
+			)
+			return
+		}
+		u, err := url.Parse(spec.Vault.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			*allErrs = append(*allErrs, field.Invalid(basePath.Child("vault").Child("url"),
+				spec.Vault.URL, "must be a valid http or https URL"),
+			)
+		}
+	}
+}
+
+// ValidateSimpleCryptoActiveKEK validates that the active Simple Crypto KEK is one of the configured KEKs
+func (spec *BarbicanSpec) ValidateSimpleCryptoActiveKEK(basePath *field.Path, allErrs *field.ErrorList) {
+	active := spec.PasswordSelectors.SimpleCryptoActiveKEK
+	if active == "" || active == spec.PasswordSelectors.SimpleCryptoKEK {
+		return
+	}
+	if !slices.Contains(spec.PasswordSelectors.SimpleCryptoAdditionalKEKs, active) {
+		*allErrs = append(*allErrs, field.Invalid(basePath.Child("passwordSelectors").Child("simplecryptoactivekek"),
+			active, "must be simplecryptokek or one of simplecryptoadditionalkeks"),
+		)
 	}
 }
 
@@ -162,6 +413,14 @@ func (spec *BarbicanSpecCore) ValidateCreate(basePath *field.Path, namespace str
 		basePath.Child("barbicanAPI").Child("override").Child("service"),
 		spec.BarbicanAPI.Override.Service)...)
 
+	allErrs = append(allErrs, spec.ValidateDefaultConfigOverwrite(basePath)...)
+	allErrs = append(allErrs, spec.ValidateLogging(basePath)...)
+	allErrs = append(allErrs, spec.ValidateAutoscaling(basePath)...)
+	allErrs = append(allErrs, spec.ValidatePodDisruptionBudget(basePath)...)
+	allErrs = append(allErrs, spec.ValidateRolloutStrategy(basePath)...)
+	allErrs = append(allErrs, spec.ValidateDBBackup(basePath)...)
+	allErrs = append(allErrs, spec.ValidateExtraMounts(basePath)...)
+	allErrs = append(allErrs, spec.ValidateDatabase(basePath)...)
 	allErrs = append(allErrs, spec.ValidateBarbicanTopology(basePath, namespace)...)
 	return allWarns, allErrs
 }
@@ -212,6 +471,46 @@ func (spec *BarbicanSpec) ValidateUpdate(old BarbicanSpec, basePath *field.Path,
 	// pkcs11 verifications
 	spec.ValidatePKCS11(basePath, &allErrs)
 
+	// kmip verifications
+	spec.ValidateKMIP(basePath, &allErrs)
+
+	// vault verifications
+	spec.ValidateVault(basePath, &allErrs)
+
+	// simple crypto verifications
+	spec.ValidateSimpleCryptoActiveKEK(basePath, &allErrs)
+
+	// secret store migration verifications
+	spec.ValidateSecretStoreMigration(basePath, &allErrs)
+
+	// project secret store verifications
+	spec.ValidateProjectSecretStores(basePath, &allErrs)
+
+	// default config overwrite verifications
+	allErrs = append(allErrs, spec.ValidateDefaultConfigOverwrite(basePath)...)
+
+	// logging verifications
+	allErrs = append(allErrs, spec.ValidateLogging(basePath)...)
+
+	// autoscaling verifications
+	allErrs = append(allErrs, spec.ValidateAutoscaling(basePath)...)
+
+	// pod disruption budget verifications
+	allErrs = append(allErrs, spec.ValidatePodDisruptionBudget(basePath)...)
+
+	// rollout strategy verifications
+	allErrs = append(allErrs, spec.ValidateRolloutStrategy(basePath)...)
+
+	// db backup verifications
+	allErrs = append(allErrs, spec.ValidateDBBackup(basePath)...)
+
+	// extra mounts verifications
+	allErrs = append(allErrs, spec.ValidateExtraMounts(basePath)...)
+
+	// database verifications
+	allErrs = append(allErrs, spec.ValidateDatabase(basePath)...)
+	allErrs = append(allErrs, spec.ValidateDatabaseUpdate(old.BarbicanSpecBase, basePath)...)
+
 	allErrs = append(allErrs, spec.ValidateBarbicanTopology(basePath, namespace)...)
 	return allWarns, allErrs
 }
@@ -277,6 +576,15 @@ func (spec *BarbicanSpecCore) ValidateUpdate(old BarbicanSpecCore, basePath *fie
 		basePath.Child("barbicanAPI").Child("override").Child("service"),
 		spec.BarbicanAPI.Override.Service)...)
 
+	allErrs = append(allErrs, spec.ValidateDefaultConfigOverwrite(basePath)...)
+	allErrs = append(allErrs, spec.ValidateLogging(basePath)...)
+	allErrs = append(allErrs, spec.ValidateAutoscaling(basePath)...)
+	allErrs = append(allErrs, spec.ValidatePodDisruptionBudget(basePath)...)
+	allErrs = append(allErrs, spec.ValidateRolloutStrategy(basePath)...)
+	allErrs = append(allErrs, spec.ValidateDBBackup(basePath)...)
+	allErrs = append(allErrs, spec.ValidateExtraMounts(basePath)...)
+	allErrs = append(allErrs, spec.ValidateDatabase(basePath)...)
+	allErrs = append(allErrs, spec.ValidateDatabaseUpdate(old.BarbicanSpecBase, basePath)...)
 	allErrs = append(allErrs, spec.ValidateBarbicanTopology(basePath, namespace)...)
 	return allWarns, allErrs
 }
@@ -320,6 +628,320 @@ func (spec *BarbicanSpecCore) SetDefaultRouteAnnotations(annotations map[string]
 	annotations[haProxyAnno] = timeout
 }
 
+// reservedConfigOverwriteFiles are the files rendered by the operator in the
+// config-data Secrets, the defaultConfigOverwrite must not replace them. The
+// kolla config files, named <service>-config.json, are reserved as well.
+var reservedConfigOverwriteFiles = []string{
+	"00-default.conf",
+	"01-custom.conf",
+	"01-service-defaults.conf",
+	"02-service-custom.conf",
+	"03-secrets-custom.conf",
+	"10-barbican_wsgi_main.conf",
+	"httpd.conf",
+	"ssl.conf",
+	"mime.conf",
+	"main",
+	"my.cnf",
+	"kolla_extend_start",
+	"barbican-worker",
+	"barbican-keystone-listener",
+	"Chrystoki.conf",
+	"cknfastrc",
+	"proteccio.rc",
+	"softhsm2.conf",
+	"ACID",
+	"ACSecret",
+}
+
+// ValidateDefaultConfigOverwrite - Returns an ErrorList if a file of the
+// defaultConfigOverwrite is not a plain file name, or replaces a file rendered
+// by the operator
+func ValidateDefaultConfigOverwrite(overwrite map[string]string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, name := range slices.Sorted(maps.Keys(overwrite)) {
+		switch {
+		case name == "" || name == "." || name == ".." || filepath.Base(name) != name:
+			allErrs = append(allErrs, field.Invalid(path.Key(name), name,
+				"must be a file name without a directory"))
+		case slices.Contains(reservedConfigOverwriteFiles, name) || strings.HasSuffix(name, "-config.json"):
+			allErrs = append(allErrs, field.Forbidden(path.Key(name),
+				"the file is generated by the operator and can not be overwritten"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateDefaultConfigOverwrite - Returns an ErrorList if the top level or a
+// service defaultConfigOverwrite replaces a file rendered by the operator
+func (spec *BarbicanSpecCore) ValidateDefaultConfigOverwrite(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, ValidateDefaultConfigOverwrite(
+		spec.DefaultConfigOverwrite, basePath.Child("defaultConfigOverwrite"))...)
+	allErrs = append(allErrs,
+		spec.BarbicanAPI.ValidateDefaultConfigOverwrite(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs,
+		spec.BarbicanKeystoneListener.ValidateDefaultConfigOverwrite(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs,
+		spec.BarbicanWorker.ValidateDefaultConfigOverwrite(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidateDefaultConfigOverwrite - Returns an ErrorList if the top level or a
+// service defaultConfigOverwrite replaces a file rendered by the operator
+func (spec *BarbicanSpec) ValidateDefaultConfigOverwrite(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, ValidateDefaultConfigOverwrite(
+		spec.DefaultConfigOverwrite, basePath.Child("defaultConfigOverwrite"))...)
+	allErrs = append(allErrs,
+		spec.BarbicanAPI.ValidateDefaultConfigOverwrite(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs,
+		spec.BarbicanKeystoneListener.ValidateDefaultConfigOverwrite(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs,
+		spec.BarbicanWorker.ValidateDefaultConfigOverwrite(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// logModuleRegexp matches the Python module names that can be safely rendered
+// in logging.conf and default_log_levels
+var logModuleRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// ValidateLogging - Returns an ErrorList if a module of the logging is not a
+// Python module name
+func ValidateLogging(logging *BarbicanLoggingTemplate, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if logging == nil {
+		return allErrs
+	}
+	for _, module := range slices.Sorted(maps.Keys(logging.ModuleLevels)) {
+		if !logModuleRegexp.MatchString(module) {
+			allErrs = append(allErrs, field.Invalid(path.Child("moduleLevels").Key(module), module,
+				"must be a Python module name"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateLogging - Returns an ErrorList if the top level or a service
+// logging is invalid
+func (spec *BarbicanSpecCore) ValidateLogging(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, ValidateLogging(spec.Logging, basePath.Child("logging"))...)
+	allErrs = append(allErrs, spec.BarbicanAPI.ValidateLogging(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs, spec.BarbicanKeystoneListener.ValidateLogging(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs, spec.BarbicanWorker.ValidateLogging(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidateLogging - Returns an ErrorList if the top level or a service
+// logging is invalid
+func (spec *BarbicanSpec) ValidateLogging(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, ValidateLogging(spec.Logging, basePath.Child("logging"))...)
+	allErrs = append(allErrs, spec.BarbicanAPI.ValidateLogging(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs, spec.BarbicanKeystoneListener.ValidateLogging(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs, spec.BarbicanWorker.ValidateLogging(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidatePodDisruptionBudget - Returns an ErrorList if a service
+// PodDisruptionBudget is invalid
+func (spec *BarbicanSpecCore) ValidatePodDisruptionBudget(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, spec.BarbicanAPI.ValidatePodDisruptionBudget(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs, spec.BarbicanKeystoneListener.ValidatePodDisruptionBudget(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs, spec.BarbicanWorker.ValidatePodDisruptionBudget(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidatePodDisruptionBudget - Returns an ErrorList if a service
+// PodDisruptionBudget is invalid
+func (spec *BarbicanSpec) ValidatePodDisruptionBudget(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, spec.BarbicanAPI.ValidatePodDisruptionBudget(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs, spec.BarbicanKeystoneListener.ValidatePodDisruptionBudget(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs, spec.BarbicanWorker.ValidatePodDisruptionBudget(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidateRolloutStrategy - Returns an ErrorList if a service rollout
+// strategy is invalid
+func (spec *BarbicanSpecCore) ValidateRolloutStrategy(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, spec.BarbicanAPI.ValidateRolloutStrategy(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs, spec.BarbicanKeystoneListener.ValidateRolloutStrategy(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs, spec.BarbicanWorker.ValidateRolloutStrategy(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidateRolloutStrategy - Returns an ErrorList if a service rollout
+// strategy is invalid
+func (spec *BarbicanSpec) ValidateRolloutStrategy(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, spec.BarbicanAPI.ValidateRolloutStrategy(basePath.Child("barbicanAPI"))...)
+	allErrs = append(allErrs, spec.BarbicanKeystoneListener.ValidateRolloutStrategy(basePath.Child("barbicanKeystoneListener"))...)
+	allErrs = append(allErrs, spec.BarbicanWorker.ValidateRolloutStrategy(basePath.Child("barbicanWorker"))...)
+	return allErrs
+}
+
+// ValidateDBBackup - Returns an ErrorList if the database dumps are not
+// written to exactly one of a PersistentVolumeClaim or an object store
+func (spec *BarbicanSpecBase) ValidateDBBackup(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	backup := spec.DBBackup
+	if backup == nil {
+		return allErrs
+	}
+	path := basePath.Child("dbBackup")
+	if backup.PersistentVolumeClaim == "" && backup.ObjectStoreSecret == "" {
+		allErrs = append(allErrs, field.Required(path,
+			"one of persistentVolumeClaim or objectStoreSecret is required"))
+	} else if backup.PersistentVolumeClaim != "" && backup.ObjectStoreSecret != "" {
+		allErrs = append(allErrs, field.Forbidden(path.Child("objectStoreSecret"),
+			"persistentVolumeClaim and objectStoreSecret can not be set together"))
+	}
+	return allErrs
+}
+
+// extraMountsPods - the propagation the extraMounts are selected with for each
+// of the pods of the Barbican
+var extraMountsPods = map[string][]storage.PropagationType{
+	"BarbicanAPI":              {"Barbican", "BarbicanAPI"},
+	"BarbicanWorker":           {"Barbican", "BarbicanWorker"},
+	"BarbicanKeystoneListener": {"Barbican", "BarbicanKeystoneListener"},
+	"DBSync":                   {"Barbican", storage.DBSync},
+}
+
+// ValidateExtraMounts - Returns an ErrorList if the extraMounts propagated to
+// a pod define different volumes with the same name, or different mounts at
+// the same path. The same volume or mount listed several times is allowed.
+func (spec *BarbicanSpecBase) ValidateExtraMounts(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	path := basePath.Child("extraMounts")
+	reported := map[string]bool{}
+	for _, pod := range slices.Sorted(maps.Keys(extraMountsPods)) {
+		volumes := map[string]storage.VolumeSource{}
+		mounts := map[string]corev1.VolumeMount{}
+		for _, extraMount := range spec.ExtraMounts {
+			for _, vm := range extraMount.Propagate(extraMountsPods[pod]) {
+				for _, v := range vm.Volumes {
+					source, found := volumes[v.Name]
+					if !found {
+						volumes[v.Name] = v.VolumeSource
+					} else if !equality.Semantic.DeepEqual(source, v.VolumeSource) && !reported["volume/"+v.Name] {
+						reported["volume/"+v.Name] = true
+						allErrs = append(allErrs, field.Invalid(path, v.Name, fmt.Sprintf(
+							"different volumes are named %s in the %s pods", v.Name, pod)))
+					}
+				}
+				for _, m := range vm.Mounts {
+					mount, found := mounts[m.MountPath]
+					if !found {
+						mounts[m.MountPath] = m
+					} else if !equality.Semantic.DeepEqual(mount, m) && !reported["mount/"+m.MountPath] {
+						reported["mount/"+m.MountPath] = true
+						allErrs = append(allErrs, field.Invalid(path, m.MountPath, fmt.Sprintf(
+							"different volumes are mounted at %s in the %s pods", m.MountPath, pod)))
+					}
+				}
+			}
+		}
+	}
+	return allErrs
+}
+
+// ValidateDatabase - Returns an ErrorList if the settings of the DB do not
+// match its type. A mariadb DB is created in the databaseInstance, a
+// postgresql DB is given in externalDatabase and can not be dumped by the
+// dbBackup, which uses mysqldump.
+func (spec *BarbicanSpecBase) ValidateDatabase(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch spec.DatabaseType {
+	case DatabaseTypePostgreSQL:
+		if spec.ExternalDatabase == nil {
+			allErrs = append(allErrs, field.Required(basePath.Child("externalDatabase"),
+				"externalDatabase is required when databaseType is postgresql"))
+		}
+		if spec.DBBackup != nil {
+			allErrs = append(allErrs, field.Forbidden(basePath.Child("dbBackup"),
+				"dbBackup is only supported when databaseType is mariadb"))
+		}
+	default:
+		if spec.DatabaseInstance == "" {
+			allErrs = append(allErrs, field.Required(basePath.Child("databaseInstance"),
+				"databaseInstance is required when databaseType is mariadb"))
+		}
+		if spec.ExternalDatabase != nil {
+			allErrs = append(allErrs, field.Forbidden(basePath.Child("externalDatabase"),
+				"externalDatabase is only supported when databaseType is postgresql"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateDatabaseUpdate - Returns an ErrorList if the type of the DB changed,
+// the data of Barbican is not migrated to the new DB
+func (spec *BarbicanSpecBase) ValidateDatabaseUpdate(old BarbicanSpecBase, basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if old.DatabaseType != "" && spec.DatabaseType != old.DatabaseType {
+		allErrs = append(allErrs, field.Forbidden(basePath.Child("databaseType"),
+			"databaseType can not be changed"))
+	}
+	return allErrs
+}
+
+// ValidateAutoscaling - Returns an ErrorList if the replicas limits of the
+// autoscaling are inverted, or if a utilization target misses the matching
+// resource request the utilization is relative to
+func ValidateAutoscaling(
+	autoscaling *BarbicanAutoscalingTemplate,
+	resources corev1.ResourceRequirements,
+	path *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if autoscaling == nil {
+		return allErrs
+	}
+	if autoscaling.MinReplicas != nil && *autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(path.Child("minReplicas"), *autoscaling.MinReplicas,
+			"must not be greater than maxReplicas"))
+	}
+	// the default target is a CPU utilization
+	cpuTarget := autoscaling.TargetCPUUtilizationPercentage != nil ||
+		autoscaling.TargetMemoryUtilizationPercentage == nil
+	if _, ok := resources.Requests[corev1.ResourceCPU]; cpuTarget && !ok {
+		allErrs = append(allErrs, field.Required(path.Child("targetCPUUtilizationPercentage"),
+			"a CPU utilization target requires a CPU request in resources"))
+	}
+	if _, ok := resources.Requests[corev1.ResourceMemory]; autoscaling.TargetMemoryUtilizationPercentage != nil && !ok {
+		allErrs = append(allErrs, field.Required(path.Child("targetMemoryUtilizationPercentage"),
+			"a memory utilization target requires a memory request in resources"))
+	}
+	return allErrs
+}
+
+// ValidateAutoscaling - Returns an ErrorList if the autoscaling of the API or
+// the worker is invalid
+func (spec *BarbicanSpecCore) ValidateAutoscaling(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, ValidateAutoscaling(spec.BarbicanAPI.Autoscaling,
+		spec.BarbicanAPI.Resources, basePath.Child("barbicanAPI").Child("autoscaling"))...)
+	allErrs = append(allErrs, ValidateAutoscaling(spec.BarbicanWorker.Autoscaling,
+		spec.BarbicanWorker.Resources, basePath.Child("barbicanWorker").Child("autoscaling"))...)
+	return allErrs
+}
+
+// ValidateAutoscaling - Returns an ErrorList if the autoscaling of the API or
+// the worker is invalid
+func (spec *BarbicanSpec) ValidateAutoscaling(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, ValidateAutoscaling(spec.BarbicanAPI.Autoscaling,
+		spec.BarbicanAPI.Resources, basePath.Child("barbicanAPI").Child("autoscaling"))...)
+	allErrs = append(allErrs, ValidateAutoscaling(spec.BarbicanWorker.Autoscaling,
+		spec.BarbicanWorker.Resources, basePath.Child("barbicanWorker").Child("autoscaling"))...)
+	return allErrs
+}
+
 // ValidateBarbicanTopology - Returns an ErrorList if the Topology is referenced
 // on a different namespace
 func (spec *BarbicanSpecCore) ValidateBarbicanTopology(basePath *field.Path, namespace string) field.ErrorList {
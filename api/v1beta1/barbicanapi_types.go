@@ -54,6 +54,57 @@ type BarbicanAPITemplateCore struct {
 	// +kubebuilder:validation:Optional
 	// APITimeout for HAProxy and Apache defaults to Barbican APITimeout (seconds)
 	APITimeout int `json:"apiTimeout"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// WSGI - Tuning of the mod_wsgi daemon processes serving the API
+	WSGI BarbicanAPIWSGI `json:"wsgi,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Autoscaling - scale the API with a HorizontalPodAutoscaler, replicas is then ignored
+	Autoscaling *BarbicanAutoscalingTemplate `json:"autoscaling,omitempty"`
+}
+
+// BarbicanAPIWSGI - tuning of the mod_wsgi daemon processes of the Barbican API
+type BarbicanAPIWSGI struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// Processes - number of daemon processes of each API endpoint. When unset it
+	// defaults to 8, raised to 2 per CPU requested by the API.
+	Processes *int32 `json:"processes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// Threads - number of threads of each daemon process, defaults to 1
+	Threads *int32 `json:"threads,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// MaximumRequests - number of requests a daemon process serves before it is
+	// restarted, 0 or unset never restarts it
+	MaximumRequests *int32 `json:"maximumRequests,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// GracefulTimeout - seconds a daemon process waits for its requests to complete
+	// when it is restarted, unset uses the mod_wsgi default
+	GracefulTimeout *int32 `json:"gracefulTimeout,omitempty"`
+}
+
+// BarbicanAPIWSGIStatus - the mod_wsgi tuning rendered in the API config
+type BarbicanAPIWSGIStatus struct {
+	// Processes - number of daemon processes of each API endpoint
+	Processes int32 `json:"processes"`
+
+	// Threads - number of threads of each daemon process
+	Threads int32 `json:"threads"`
+
+	// MaximumRequests - number of requests a daemon process serves before it is restarted
+	MaximumRequests int32 `json:"maximumRequests,omitempty"`
+
+	// GracefulTimeout - seconds a daemon process waits for its requests when it is restarted
+	GracefulTimeout int32 `json:"gracefulTimeout,omitempty"`
 }
 
 // APIOverrideSpec to override the generated manifest of several child resources.
@@ -103,6 +154,15 @@ type BarbicanAPIStatus struct {
 
 	// LastAppliedTopology - the last applied Topology
 	LastAppliedTopology *topologyv1.TopoRef `json:"lastAppliedTopology,omitempty"`
+
+	// WSGI - the mod_wsgi tuning the API pods run with
+	WSGI *BarbicanAPIWSGIStatus `json:"wsgi,omitempty"`
+
+	// ObservedGeneration - the most recent generation observed for this
+	// service. If the observed generation is less than the spec generation,
+	// then the controller has not processed the latest changes injected by
+	// the Barbican CR (e.g. the ContainerImage)
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 //+kubebuilder:object:root=true
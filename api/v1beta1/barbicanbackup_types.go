@@ -0,0 +1,114 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackupPublicKeySelector - field of the PublicKeySecret holding the PEM
+	// encoded RSA public key
+	BackupPublicKeySelector = "publicKey"
+
+	// BackupBundleHash hash
+	BackupBundleHash = "bundle"
+)
+
+// BarbicanBackupSpec defines the desired state of BarbicanBackup
+type BarbicanBackupSpec struct {
+	// +kubebuilder:validation:Required
+	// BarbicanName - name of the Barbican to back up
+	BarbicanName string `json:"barbicanName"`
+
+	// +kubebuilder:validation:Required
+	// PersistentVolumeClaim - name of the PersistentVolumeClaim the bundle is written to
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// +kubebuilder:validation:Required
+	// PublicKeySecret - name of the Secret holding in its publicKey field the PEM encoded
+	// RSA public key the KEK Secret is encrypted to
+	PublicKeySecret string `json:"publicKeySecret"`
+
+	// +kubebuilder:validation:Optional
+	// ContainerImage - image providing mysqldump. Defaults to the MariaDB image of the
+	// operator.
+	ContainerImage string `json:"containerImage,omitempty"`
+}
+
+// BarbicanBackupStatus defines the observed state of BarbicanBackup
+type BarbicanBackupStatus struct {
+	// Bundle - name of the directory of the bundle in the PersistentVolumeClaim, it
+	// holds the database dump, the encrypted KEK Secret and the rendered config
+	Bundle string `json:"bundle,omitempty"`
+
+	// KEKSecret - name of the KEK Secret whose KEKs are encrypted in the bundle, empty when the
+	// Barbican does not use the Simple Crypto secret store
+	KEKSecret string `json:"kekSecret,omitempty"`
+
+	// KEKHash - hash of the KEKs encrypted in the bundle
+	KEKHash string `json:"kekHash,omitempty"`
+
+	// StartTime - time the backup started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime - time the bundle was completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// ObservedGeneration - the most recent generation observed for this backup
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Bundle",type="string",JSONPath=".status.bundle",description="Bundle"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// BarbicanBackup is the Schema for the barbicanbackups API
+type BarbicanBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BarbicanBackupSpec   `json:"spec,omitempty"`
+	Status BarbicanBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BarbicanBackupList contains a list of BarbicanBackup
+type BarbicanBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BarbicanBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BarbicanBackup{}, &BarbicanBackupList{})
+}
+
+// IsReady - returns true when the bundle is complete
+func (instance BarbicanBackup) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
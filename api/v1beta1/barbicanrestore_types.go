@@ -0,0 +1,137 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RestorePrivateKeySelector - field of the PrivateKeySecret holding the
+	// PEM encoded RSA private key
+	RestorePrivateKeySelector = "privateKey"
+
+	// RestoreKEKHash hash
+	RestoreKEKHash = "kek"
+
+	// RestoreDBHash hash
+	RestoreDBHash = "db"
+)
+
+// BarbicanRestoreSpec defines the desired state of BarbicanRestore
+type BarbicanRestoreSpec struct {
+	// +kubebuilder:validation:Required
+	// BarbicanName - name of the Barbican to restore, its services are only
+	// started once the restore completed
+	BarbicanName string `json:"barbicanName"`
+
+	// +kubebuilder:validation:Required
+	// PersistentVolumeClaim - name of the PersistentVolumeClaim holding the bundle
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// +kubebuilder:validation:Required
+	// Bundle - name of the directory of the bundle in the PersistentVolumeClaim, as
+	// reported in the status of the BarbicanBackup
+	Bundle string `json:"bundle"`
+
+	// +kubebuilder:validation:Required
+	// PrivateKeySecret - name of the Secret holding in its privateKey field the PEM
+	// encoded RSA private key the KEK Secret of the bundle is decrypted with
+	PrivateKeySecret string `json:"privateKeySecret"`
+
+	// +kubebuilder:validation:Optional
+	// ContainerImage - image providing the mysql client and curl. Defaults to the
+	// MariaDB image of the operator.
+	ContainerImage string `json:"containerImage,omitempty"`
+}
+
+// BarbicanRestoreStatus defines the observed state of BarbicanRestore
+type BarbicanRestoreStatus struct {
+	// KEKSecret - name of the KEK Secret the KEKs of the bundle were restored to, empty
+	// when the bundle has no KEKs
+	KEKSecret string `json:"kekSecret,omitempty"`
+
+	// StartTime - time the restore started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime - time the database dump was loaded
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// ObservedGeneration - the most recent generation observed for this restore
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Barbican",type="string",JSONPath=".spec.barbicanName",description="Barbican"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// BarbicanRestore is the Schema for the barbicanrestores API
+type BarbicanRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BarbicanRestoreSpec   `json:"spec,omitempty"`
+	Status BarbicanRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BarbicanRestoreList contains a list of BarbicanRestore
+type BarbicanRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BarbicanRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BarbicanRestore{}, &BarbicanRestoreList{})
+}
+
+// IsReady - returns true when the database dump was loaded
+func (instance BarbicanRestore) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// RbacConditionsSet - set the conditions for the rbac object
+func (instance BarbicanRestore) RbacConditionsSet(c *condition.Condition) {
+	instance.Status.Conditions.Set(c)
+}
+
+// RbacNamespace - return the namespace
+func (instance BarbicanRestore) RbacNamespace() string {
+	return instance.Namespace
+}
+
+// RbacResourceName - return the name to be used for rbac objects (serviceaccount, role, rolebinding)
+func (instance BarbicanRestore) RbacResourceName() string {
+	return "barbicanrestore-" + instance.Name
+}
+
+// IsKEKRestored - returns true when the KEK Secret of the bundle was recreated,
+// or the bundle has none
+func (instance BarbicanRestore) IsKEKRestored() bool {
+	return instance.Status.Conditions.IsTrue(BarbicanRestoreKEKReadyCondition)
+}
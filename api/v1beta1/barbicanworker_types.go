@@ -38,6 +38,11 @@ type BarbicanWorkerTemplateCore struct {
 
 	// TODO(dmendiza): Do we need a setting for number of worker processes
 	// or is replica scaling good enough?
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Autoscaling - scale the worker with a HorizontalPodAutoscaler, replicas is then ignored
+	Autoscaling *BarbicanAutoscalingTemplate `json:"autoscaling,omitempty"`
 }
 
 // BarbicanWorkerSpec defines the desired state of BarbicanWorker
@@ -83,6 +88,12 @@ type BarbicanWorkerStatus struct {
 
 	// LastAppliedTopology - the last applied Topology
 	LastAppliedTopology *topologyv1.TopoRef `json:"lastAppliedTopology,omitempty"`
+
+	// ObservedGeneration - the most recent generation observed for this
+	// service. If the observed generation is less than the spec generation,
+	// then the controller has not processed the latest changes injected by
+	// the Barbican CR (e.g. the ContainerImage)
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 //+kubebuilder:object:root=true
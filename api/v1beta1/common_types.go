@@ -4,6 +4,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rabbitmqv1 "github.com/openstack-k8s-operators/infra-operator/apis/rabbitmq/v1beta1"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -16,8 +18,8 @@ type BarbicanTemplate struct {
 	// ServiceUser - optional username used for this service to register in keystone
 	ServiceUser string `json:"serviceUser"`
 
-	// +kubebuilder:validation:Required
-	// MariaDB instance name
+	// +kubebuilder:validation:Optional
+	// MariaDB instance name, required when DatabaseType is mariadb
 	// TODO(dmendiza): Is this comment right?
 	// Right now required by the maridb-operator to get the credentials from the instance to create the DB
 	// Might not be required in future
@@ -28,6 +30,17 @@ type BarbicanTemplate struct {
 	// DatabaseAccount - optional MariaDBAccount CR name used for barbican DB, defaults to barbican
 	DatabaseAccount string `json:"databaseAccount"`
 
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=mariadb
+	// DatabaseType - type of the barbican DB. A mariadb DB is created in the DatabaseInstance
+	// with a MariaDBDatabase and a MariaDBAccount, a postgresql DB is managed outside of the
+	// operator and given in ExternalDatabase.
+	DatabaseType DatabaseType `json:"databaseType"`
+
+	// +kubebuilder:validation:Optional
+	// ExternalDatabase - the externally managed DB, required when DatabaseType is postgresql
+	ExternalDatabase *BarbicanExternalDatabaseTemplate `json:"externalDatabase,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// RabbitMQ instance name
 	// Needed to request a transportURL that is created and used in Barbican
@@ -64,6 +77,12 @@ type BarbicanTemplate struct {
 	// +kubebuilder:validation:Optional
 	PKCS11 *BarbicanPKCS11Template `json:"pkcs11,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	KMIP *BarbicanKMIPTemplate `json:"kmip,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Vault *BarbicanVaultTemplate `json:"vault,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:MaxItems=2
@@ -73,6 +92,13 @@ type BarbicanTemplate struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="simple_crypto"
 	GlobalDefaultSecretStore SecretStore `json:"globalDefaultSecretStore" yaml:"globalDefaultSecretStore"`
+
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// ExtraMounts - extra volumes mounted into the Barbican pods. The propagation
+	// selects the pods: Barbican for all of them, BarbicanAPI, BarbicanWorker,
+	// BarbicanKeystoneListener and DBSync for the matching component.
+	ExtraMounts []storage.VolMounts `json:"extraMounts,omitempty"`
 }
 
 // BarbicanComponentTemplate - Variables used by every sub-component of Barbican
@@ -98,9 +124,11 @@ type BarbicanComponentTemplate struct {
 	CustomServiceConfig string `json:"customServiceConfig,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	// ConfigOverwrite - interface to overwrite default config files like e.g. policy.json.
-	// But can also be used to add additional files. Those get added to the service config dir in /etc/<service> .
-	// TODO: -> implement
+	// ConfigOverwrite - interface to overwrite default config files like e.g. policy.yaml, logging.conf
+	// or barbican-api-paste.ini. But can also be used to add additional files. Those get added to the
+	// service config dir in /etc/<service> , on top of the ones of the top level defaultConfigOverwrite.
+	// A logging.conf replaces the one rendered from the logging section. Files generated by the operator,
+	// like 00-default.conf or httpd.conf, can not be overwritten.
 	DefaultConfigOverwrite map[string]string `json:"defaultConfigOverwrite,omitempty"`
 
 	// +kubebuilder:validation:Optional
@@ -109,6 +137,11 @@ type BarbicanComponentTemplate struct {
 	// /etc/<service>/<service>.conf.d directory as a custom config file.
 	CustomServiceConfigSecrets []string `json:"customServiceConfigSecrets,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// Logging - overrides the top level logging for this service. Unset fields keep the top
+	// level value, the module levels are merged with the top level ones.
+	Logging *BarbicanLoggingTemplate `json:"logging,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// Resources - Compute Resources required by this service (Limits/Requests).
 	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
@@ -122,10 +155,188 @@ type BarbicanComponentTemplate struct {
 	// TopologyRef to apply the Topology defined by the associated CR referenced
 	// by name
 	TopologyRef *topologyv1.TopoRef `json:"topologyRef,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PodDisruptionBudget - limits the pods of this service evicted at once, e.g. during
+	// node drains. Without minAvailable and maxUnavailable, all the replicas but one must
+	// stay available, a single replica can always be evicted.
+	PodDisruptionBudget *BarbicanPodDisruptionBudgetTemplate `json:"podDisruptionBudget,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Probes - overrides the timings of the liveness, readiness and startup probes of this service
+	Probes *BarbicanProbesTemplate `json:"probes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// RolloutStrategy - controls how the pods of this service are replaced on updates
+	RolloutStrategy *BarbicanRolloutStrategyTemplate `json:"rolloutStrategy,omitempty"`
+}
+
+// BarbicanRolloutStrategyTemplate - the rolling update of the Deployment of a Barbican service
+type BarbicanRolloutStrategyTemplate struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	// MaxSurge - number or percentage of pods created above the replicas during a rollout
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	// MaxUnavailable - number or percentage of pods that can be unavailable during a rollout
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// MinReadySeconds - seconds a new pod must be ready before it counts as available
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// ProgressDeadlineSeconds - seconds without progress after which the rollout is reported
+	// as stalled
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Paused - holds the rollout of the changes to the pods of this service
+	Paused bool `json:"paused,omitempty"`
+}
+
+// BarbicanPodDisruptionBudgetTemplate - the PodDisruptionBudget of a Barbican service
+type BarbicanPodDisruptionBudgetTemplate struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	// MinAvailable - number or percentage of pods that must stay available,
+	// can not be set with maxUnavailable
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	// MaxUnavailable - number or percentage of pods that can be unavailable,
+	// can not be set with minAvailable
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// BarbicanProbesTemplate - the probe overrides of a Barbican service
+type BarbicanProbesTemplate struct {
+	// +kubebuilder:validation:Optional
+	// LivenessProbe - restarts the service container when it fails
+	LivenessProbe *BarbicanProbeTemplate `json:"livenessProbe,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ReadinessProbe - removes the pod from the service endpoints when it fails
+	ReadinessProbe *BarbicanProbeTemplate `json:"readinessProbe,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// StartupProbe - holds the liveness and readiness probes until the service started,
+	// e.g. during a slow first login to an HSM
+	StartupProbe *BarbicanProbeTemplate `json:"startupProbe,omitempty"`
+}
+
+// BarbicanProbeTemplate - the timings of a probe, unset fields keep the defaults of the operator
+type BarbicanProbeTemplate struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// InitialDelaySeconds - seconds after the container started before the probe runs
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// TimeoutSeconds - seconds after which the probe times out
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// PeriodSeconds - seconds between two runs of the probe
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// FailureThreshold - consecutive failures after which the probe is failed
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// BarbicanAutoscalingTemplate - horizontal autoscaling of a Barbican service
+type BarbicanAutoscalingTemplate struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	// MinReplicas - lower limit of the replicas set by the autoscaler
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	// MaxReplicas - upper limit of the replicas set by the autoscaler
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// TargetCPUUtilizationPercentage - target average CPU utilization of the pods,
+	// relative to their CPU request. Defaults to 80 when no target is set.
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// TargetMemoryUtilizationPercentage - target average memory utilization of the
+	// pods, relative to their memory request
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// LogLevel is the level of the Python loggers of the Barbican services
+// +kubebuilder:validation:Enum=DEBUG;INFO;WARNING;ERROR;CRITICAL
+type LogLevel string
+
+const (
+	// LogLevelDebug -
+	LogLevelDebug LogLevel = "DEBUG"
+	// LogLevelInfo -
+	LogLevelInfo LogLevel = "INFO"
+	// LogLevelWarning -
+	LogLevelWarning LogLevel = "WARNING"
+	// LogLevelError -
+	LogLevelError LogLevel = "ERROR"
+	// LogLevelCritical -
+	LogLevelCritical LogLevel = "CRITICAL"
+)
+
+// LogMode is where the Barbican services write their logs
+// +kubebuilder:validation:Enum=file;stdout
+type LogMode string
+
+const (
+	// LogModeFile - the services log to a file of an emptyDir, a log container
+	// of each pod streams it
+	LogModeFile LogMode = "file"
+	// LogModeStdout - the services log to stdout and stderr, the pods have no
+	// log container
+	LogModeStdout LogMode = "stdout"
+)
+
+// BarbicanLoggingTemplate - log level and format of the Barbican services
+type BarbicanLoggingTemplate struct {
+	// +kubebuilder:validation:Optional
+	// Mode - file, the default, logs to a file streamed by a log container of each pod.
+	// stdout logs straight to stdout and stderr, including the httpd logs of the API,
+	// without a log container.
+	Mode LogMode `json:"mode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Level - level of the root logger, INFO when not set at any level. DEBUG also sets
+	// the oslo.log debug option.
+	Level LogLevel `json:"level,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// JSON - log JSON records with the oslo.log JSONFormatter instead of plain text lines
+	JSON *bool `json:"json,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ModuleLevels - level of the loggers of Python modules, keyed by module name, e.g.
+	// oslo_messaging or barbican.plugin.crypto. They are added to the oslo.log
+	// default_log_levels, a module listed there gets the level set here.
+	ModuleLevels map[string]LogLevel `json:"moduleLevels,omitempty"`
 }
 
 // SecretStore type is used by the EnabledSecretStores variable inside the specification.
-// +kubebuilder:validation:Enum=simple_crypto;pkcs11
+// +kubebuilder:validation:Enum=simple_crypto;pkcs11;kmip;vault
 type SecretStore string
 
 const (
@@ -135,8 +346,31 @@ const (
 	// SecretStorePKCS11 -
 	SecretStorePKCS11 SecretStore = "pkcs11"
 
+	// SecretStoreKMIP -
+	SecretStoreKMIP SecretStore = "kmip"
+
+	// SecretStoreVault -
+	SecretStoreVault SecretStore = "vault"
+
 	// DefaultPKCS11ClientDataPath is the default path for PKCS11 client data
 	DefaultPKCS11ClientDataPath = "/etc/hsm-client"
+	// DefaultSoftHSMStorageRequest is the default size of the SoftHSM token PersistentVolumeClaim
+	DefaultSoftHSMStorageRequest = "100Mi"
+)
+
+// HSMVendor - vendor of the HSM used by the pkcs11 secret store
+// +kubebuilder:validation:Enum=luna;nshield;proteccio;softhsm
+type HSMVendor string
+
+const (
+	// HSMVendorLuna - Thales Luna Network HSM
+	HSMVendorLuna HSMVendor = "luna"
+	// HSMVendorNShield - Entrust nShield HSM
+	HSMVendorNShield HSMVendor = "nshield"
+	// HSMVendorProteccio - Eviden Proteccio HSM
+	HSMVendorProteccio HSMVendor = "proteccio"
+	// HSMVendorSoftHSM - SoftHSM2 token managed by the operator, for development and CI
+	HSMVendorSoftHSM HSMVendor = "softhsm"
 )
 
 // BarbicanPKCS11Template - Includes common HSM properties
@@ -145,15 +379,234 @@ type BarbicanPKCS11Template struct {
         // OpenShift secret that stores the password to login to the PKCS11 session
         LoginSecret string `json:"loginSecret"`
 
-        // +kubebuilder:validation:Required
+        // +kubebuilder:validation:Optional
         // The OpenShift secret that stores the HSM client data.
         // These will be mounted to /var/lib/config-data/hsm
+        // Required unless vendor is softhsm.
         ClientDataSecret string `json:"clientDataSecret"`
 
         // +kubebuilder:validation:Optional
 	// +kubebuilder:default="/etc/hsm-client"
         // Location to which kolla will copy the data in ClientDataSecret.
         ClientDataPath string `json:"clientDataPath"`
+
+	// +kubebuilder:validation:Optional
+	// Vendor of the HSM. When set, the operator renders the vendor client
+	// configuration (Chrystoki.conf, cknfastrc or proteccio.rc), sets the
+	// environment the vendor library expects and defaults libraryPath.
+	// The certificates referenced by the client configuration are read from
+	// ClientDataSecret: client.pem, clientKey.pem and CAFile.pem for luna,
+	// client.crt, client.key and server.crt for proteccio.
+	Vendor HSMVendor `json:"vendor,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HSM servers the client connects to, required by the luna and proteccio vendors
+	Servers []string `json:"servers,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// SoftHSM token settings, used when vendor is softhsm
+	SoftHSM *BarbicanSoftHSMTemplate `json:"softHSM,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Label of the MKEK used to wrap the project KEKs. Changing it generates a
+	// new MKEK and rewraps the existing project KEKs with it.
+	MKEKLabel string `json:"mkekLabel,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Label of the HMAC key used to sign the wrapped project KEKs. Changing it
+	// generates a new HMAC key and rewraps the existing project KEKs with it.
+	HMACLabel string `json:"hmacLabel,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Path to the PKCS11 library provided by the HSM vendor
+	LibraryPath string `json:"libraryPath,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Label of the token to use
+	TokenLabel string `json:"tokenLabel,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// ID of the slot to use
+	SlotID *int `json:"slotId,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Length in bytes of the generated MKEK, one of 16, 24 or 32
+	MKEKLength int `json:"mkekLength,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// Length in bytes of the generated HMAC key
+	HMACLength int `json:"hmacLength,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Mechanism used to encrypt the secrets, e.g. CKM_AES_GCM
+	EncryptionMechanism string `json:"encryptionMechanism,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Key type of the HMAC key, e.g. CKK_GENERIC_SECRET
+	HMACKeyType string `json:"hmacKeyType,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Mechanism used to generate the HMAC key, e.g. CKM_GENERIC_SECRET_KEY_GEN
+	HMACKeygenMechanism string `json:"hmacKeygenMechanism,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Mechanism used to sign the wrapped project KEKs, e.g. CKM_SHA256_HMAC
+	HMACMechanism string `json:"hmacMechanism,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Mechanism used to wrap the project KEKs, e.g. CKM_AES_CBC_PAD
+	KeyWrapMechanism string `json:"keyWrapMechanism,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Generate the IV for CKM_AES_GCM in the plugin rather than in the HSM
+	AESGCMGenerateIV *bool `json:"aesGCMGenerateIV,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Allow the PKCS11 library to use the native operating system locking model
+	OSLockingOK *bool `json:"osLockingOK,omitempty"`
+}
+
+// BarbicanSoftHSMTemplate - settings of the SoftHSM2 token created by the operator.
+// The token is initialised by a Job in a PersistentVolumeClaim shared by the
+// pkcs11-prep Job and the API and worker pods. When LoginSecret does not exist,
+// it is created with a random PIN under the PKCS11Pin selector and the SO PIN
+// under SoftHSMSOPin.
+type BarbicanSoftHSMTemplate struct {
+	// +kubebuilder:validation:Optional
+	// Storage class of the token PersistentVolumeClaim
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=ReadWriteOnce;ReadWriteMany
+	// +kubebuilder:default=ReadWriteMany
+	// Access mode of the token PersistentVolumeClaim. The API and worker pods
+	// share the token, ReadWriteOnce only works when they all run on a single
+	// node.
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="100Mi"
+	// Size of the token PersistentVolumeClaim
+	StorageRequest string `json:"storageRequest"`
+}
+
+// BarbicanKMIPTemplate - Includes the properties needed to reach a KMIP server
+type BarbicanKMIPTemplate struct {
+	// +kubebuilder:validation:Required
+	// Hostname or IP address of the KMIP server
+	Host string `json:"host"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=5696
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// Port of the KMIP server
+	Port int32 `json:"port"`
+
+	// +kubebuilder:validation:Required
+	// OpenShift secret that stores the client certificate (tls.crt) and
+	// key (tls.key) used to authenticate against the KMIP server
+	ClientCertSecret string `json:"clientCertSecret"`
+
+	// +kubebuilder:validation:Required
+	// OpenShift secret that stores the CA certificate (ca.crt) used to
+	// verify the KMIP server
+	CASecret string `json:"caSecret"`
+}
+
+// DatabaseType is the type of the barbican DB
+// +kubebuilder:validation:Enum=mariadb;postgresql
+type DatabaseType string
+
+const (
+	// DatabaseTypeMariaDB - a DB created through the mariadb-operator
+	DatabaseTypeMariaDB DatabaseType = "mariadb"
+
+	// DatabaseTypePostgreSQL - an externally managed PostgreSQL DB
+	DatabaseTypePostgreSQL DatabaseType = "postgresql"
+
+	// ExternalDatabaseUsernameSelector - field of the ExternalDatabase Secret holding the username
+	ExternalDatabaseUsernameSelector = "username"
+
+	// ExternalDatabasePasswordSelector - field of the ExternalDatabase Secret holding the password
+	ExternalDatabasePasswordSelector = "password"
+)
+
+// BarbicanExternalDatabaseTemplate - an externally managed DB, the database and its user
+// have to exist
+type BarbicanExternalDatabaseTemplate struct {
+	// +kubebuilder:validation:Required
+	// Host - hostname or IP address of the DB server
+	Host string `json:"host"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=5432
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// Port - port of the DB server
+	Port int32 `json:"port"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=barbican
+	// Name - name of the database
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Required
+	// Secret - OpenShift secret that stores the credentials of the DB user in its
+	// "username" and "password" keys
+	Secret string `json:"secret"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=disable;allow;prefer;require;verify-ca;verify-full
+	// SSLMode - sslmode of the connection. The server certificate is verified with the
+	// CA bundle of the TLS settings when one is set.
+	SSLMode string `json:"sslMode,omitempty"`
+}
+
+// VaultAuthMethod is the method used by Barbican to authenticate against Vault
+// +kubebuilder:validation:Enum=token;approle
+type VaultAuthMethod string
+
+const (
+	// VaultAuthMethodToken -
+	VaultAuthMethodToken VaultAuthMethod = "token"
+
+	// VaultAuthMethodAppRole -
+	VaultAuthMethodAppRole VaultAuthMethod = "approle"
+)
+
+// BarbicanVaultTemplate - Includes the properties needed to reach a HashiCorp Vault server
+type BarbicanVaultTemplate struct {
+	// +kubebuilder:validation:Required
+	// URL of the Vault server, e.g. https://vault.example.com:8200
+	URL string `json:"url"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=token
+	// AuthMethod - method used to authenticate against Vault
+	AuthMethod VaultAuthMethod `json:"authMethod"`
+
+	// +kubebuilder:validation:Required
+	// OpenShift secret that stores the Vault credentials. When AuthMethod is
+	// token it must contain the "token" key, when it is approle it must
+	// contain the "roleID" and "secretID" keys.
+	AuthSecret string `json:"authSecret"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=secret
+	// KVMountpoint - mountpoint of the KV secrets engine used to store secrets
+	KVMountpoint string `json:"kvMountpoint"`
+
+	// +kubebuilder:validation:Optional
+	// OpenShift secret that stores the CA certificate (ca.crt) used to
+	// verify the Vault server
+	CASecret string `json:"caSecret,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Namespace - Vault Enterprise namespace
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // AuthSpec defines authentication parameters
@@ -181,6 +634,20 @@ type PasswordSelector struct {
 	// Fields containing additional Key Encryption Keys(KEK) used for the Simple Crypto backend
 	// It is expected that these fields will exist in the secret referenced in SimpleCryptoBackendSecret
 	SimpleCryptoAdditionalKEKs []string `json:"simplecryptoadditionalkeks,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Field containing the active Key Encryption Key(KEK) used for the Simple Crypto backend.
+	// It must be SimpleCryptoKEK or one of SimpleCryptoAdditionalKEKs and defaults to SimpleCryptoKEK.
+	// Changing it rewraps the existing project KEKs with the new active KEK, after which the
+	// previously active KEK is no longer rendered in the service config.
+	SimpleCryptoActiveKEK string `json:"simplecryptoactivekek,omitempty"`
+}
+
+// GetSimpleCryptoActiveKEK returns the field containing the active Simple Crypto KEK
+func (p PasswordSelector) GetSimpleCryptoActiveKEK() string {
+	if p.SimpleCryptoActiveKEK == "" {
+		return p.SimpleCryptoKEK
+	}
+	return p.SimpleCryptoActiveKEK
 }
 
 // ValidateTopology -
@@ -194,3 +661,63 @@ func (instance *BarbicanComponentTemplate) ValidateTopology(
 		*basePath.Child("topologyRef"), namespace)...)
 	return allErrs
 }
+
+// ValidateDefaultConfigOverwrite -
+func (instance *BarbicanComponentTemplate) ValidateDefaultConfigOverwrite(
+	basePath *field.Path,
+) field.ErrorList {
+	return ValidateDefaultConfigOverwrite(
+		instance.DefaultConfigOverwrite,
+		basePath.Child("defaultConfigOverwrite"))
+}
+
+// ValidatePodDisruptionBudget -
+func (instance *BarbicanComponentTemplate) ValidatePodDisruptionBudget(
+	basePath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	budget := instance.PodDisruptionBudget
+	if budget != nil && budget.MinAvailable != nil && budget.MaxUnavailable != nil {
+		allErrs = append(allErrs, field.Forbidden(basePath.Child("podDisruptionBudget").Child("maxUnavailable"),
+			"minAvailable and maxUnavailable can not be set together"))
+	}
+	return allErrs
+}
+
+// ValidateRolloutStrategy -
+func (instance *BarbicanComponentTemplate) ValidateRolloutStrategy(
+	basePath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	strategy := instance.RolloutStrategy
+	if strategy == nil {
+		return allErrs
+	}
+	path := basePath.Child("rolloutStrategy")
+	// unset, they keep the Kubernetes defaults of 25%
+	if isZeroIntOrPercent(strategy.MaxSurge) && isZeroIntOrPercent(strategy.MaxUnavailable) {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxUnavailable"), strategy.MaxUnavailable.String(),
+			"may not be 0 when maxSurge is 0"))
+	}
+	if strategy.ProgressDeadlineSeconds != nil && strategy.MinReadySeconds != nil &&
+		*strategy.ProgressDeadlineSeconds <= *strategy.MinReadySeconds {
+		allErrs = append(allErrs, field.Invalid(path.Child("progressDeadlineSeconds"), *strategy.ProgressDeadlineSeconds,
+			"must be greater than minReadySeconds"))
+	}
+	return allErrs
+}
+
+func isZeroIntOrPercent(value *intstr.IntOrString) bool {
+	if value == nil {
+		return false
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+	return err == nil && scaled == 0
+}
+
+// ValidateLogging -
+func (instance *BarbicanComponentTemplate) ValidateLogging(
+	basePath *field.Path,
+) field.ErrorList {
+	return ValidateLogging(instance.Logging, basePath.Child("logging"))
+}
@@ -40,3 +40,107 @@ const (
 	// BarbicanNetworkAttachmentsReadyErrorMessage -
 	BarbicanNetworkAttachmentsReadyErrorMessage = "NetworkAttachments error occurred; not all pods have interfaces with IPs as configured in NetworkAttachments: %s"
 )
+
+const (
+	// BarbicanVaultInputReadyCondition - Status=True condition which indicates
+	// that the Secrets referenced by the Vault secret store are available
+	BarbicanVaultInputReadyCondition condition.Type = "BarbicanVaultInputReady"
+)
+
+const (
+	// BarbicanVaultInputReadyInitMessage -
+	BarbicanVaultInputReadyInitMessage = "Vault input not started"
+	// BarbicanVaultInputReadyMessage -
+	BarbicanVaultInputReadyMessage = "Vault input ready"
+	// BarbicanVaultInputReadyWaitingMessage -
+	BarbicanVaultInputReadyWaitingMessage = "Vault input waiting for secret %s"
+	// BarbicanVaultInputReadyErrorMessage -
+	BarbicanVaultInputReadyErrorMessage = "Vault input error occurred %s"
+)
+
+const (
+	// BarbicanRolloutReadyCondition - Status=True condition which indicates
+	// that all the replicas of the Deployment of a service run its current
+	// pod template
+	BarbicanRolloutReadyCondition condition.Type = "BarbicanRolloutReady"
+)
+
+const (
+	// BarbicanRolloutPausedReason - the rollout of the Deployment is paused
+	BarbicanRolloutPausedReason condition.Reason = "RolloutPaused"
+)
+
+const (
+	// BarbicanRolloutReadyInitMessage -
+	BarbicanRolloutReadyInitMessage = "Deployment rollout not started"
+	// BarbicanRolloutReadyMessage -
+	BarbicanRolloutReadyMessage = "Deployment rollout completed"
+	// BarbicanRolloutReadyRunningMessage -
+	BarbicanRolloutReadyRunningMessage = "Deployment rollout in progress"
+	// BarbicanRolloutReadyPausedMessage -
+	BarbicanRolloutReadyPausedMessage = "Deployment rollout paused"
+	// BarbicanRolloutReadyStalledMessage -
+	BarbicanRolloutReadyStalledMessage = "Deployment rollout stalled: %s"
+)
+
+const (
+	// BarbicanBackupKEKReadyCondition - Status=True condition which indicates
+	// that the KEK Secret is encrypted to the public key of the backup
+	BarbicanBackupKEKReadyCondition condition.Type = "BarbicanBackupKEKReady"
+
+	// BarbicanBackupBundleReadyCondition - Status=True condition which
+	// indicates that the bundle is written to the PersistentVolumeClaim
+	BarbicanBackupBundleReadyCondition condition.Type = "BarbicanBackupBundleReady"
+
+	// BarbicanRestoreKEKReadyCondition - Status=True condition which indicates
+	// that the KEK Secret of the bundle is recreated
+	BarbicanRestoreKEKReadyCondition condition.Type = "BarbicanRestoreKEKReady"
+
+	// BarbicanRestoreDBReadyCondition - Status=True condition which indicates
+	// that the database dump of the bundle is loaded
+	BarbicanRestoreDBReadyCondition condition.Type = "BarbicanRestoreDBReady"
+)
+
+const (
+	// BarbicanBackupKEKReadyInitMessage -
+	BarbicanBackupKEKReadyInitMessage = "KEK backup not started"
+	// BarbicanBackupKEKReadyMessage -
+	BarbicanBackupKEKReadyMessage = "KEK Secret %s encrypted"
+	// BarbicanBackupKEKReadyNotNeededMessage -
+	BarbicanBackupKEKReadyNotNeededMessage = "No Simple Crypto KEK to back up"
+	// BarbicanBackupKEKReadyErrorMessage -
+	BarbicanBackupKEKReadyErrorMessage = "KEK backup error occurred %s"
+
+	// BarbicanBackupBundleReadyInitMessage -
+	BarbicanBackupBundleReadyInitMessage = "Backup bundle not started"
+	// BarbicanBackupBundleReadyWaitingMessage -
+	BarbicanBackupBundleReadyWaitingMessage = "Backup bundle is waiting for the database of Barbican %s"
+	// BarbicanBackupBundleReadyRunningMessage -
+	BarbicanBackupBundleReadyRunningMessage = "Backup bundle %s is being written"
+	// BarbicanBackupBundleReadyMessage -
+	BarbicanBackupBundleReadyMessage = "Backup bundle %s written"
+	// BarbicanBackupBundleReadyErrorMessage -
+	BarbicanBackupBundleReadyErrorMessage = "Backup bundle error occurred %s"
+
+	// BarbicanRestoreKEKReadyInitMessage -
+	BarbicanRestoreKEKReadyInitMessage = "KEK restore not started"
+	// BarbicanRestoreKEKReadyRunningMessage -
+	BarbicanRestoreKEKReadyRunningMessage = "KEK Secret is being read from bundle %s"
+	// BarbicanRestoreKEKReadyMessage -
+	BarbicanRestoreKEKReadyMessage = "KEK Secret %s restored"
+	// BarbicanRestoreKEKReadyNotNeededMessage -
+	BarbicanRestoreKEKReadyNotNeededMessage = "No KEK Secret in bundle %s"
+	// BarbicanRestoreKEKReadyErrorMessage -
+	BarbicanRestoreKEKReadyErrorMessage = "KEK restore error occurred %s"
+
+	// BarbicanRestoreDBReadyInitMessage -
+	BarbicanRestoreDBReadyInitMessage = "Database restore not started"
+	// BarbicanRestoreDBReadyWaitingMessage -
+	BarbicanRestoreDBReadyWaitingMessage = "Database restore is waiting for the database of Barbican %s"
+	// BarbicanRestoreDBReadyRunningMessage -
+	BarbicanRestoreDBReadyRunningMessage = "Database dump of bundle %s is being loaded"
+	// BarbicanRestoreDBReadyMessage -
+	BarbicanRestoreDBReadyMessage = "Database dump of bundle %s loaded"
+	// BarbicanRestoreDBReadyErrorMessage -
+	BarbicanRestoreDBReadyErrorMessage = "Database restore error occurred %s"
+)
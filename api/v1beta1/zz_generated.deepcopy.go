@@ -25,7 +25,9 @@ import (
 	topologyv1beta1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -213,6 +215,11 @@ func (in *BarbicanAPIStatus) DeepCopyInto(out *BarbicanAPIStatus) {
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	if in.WSGI != nil {
+		in, out := &in.WSGI, &out.WSGI
+		*out = new(BarbicanAPIWSGIStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanAPIStatus.
@@ -247,6 +254,12 @@ func (in *BarbicanAPITemplateCore) DeepCopyInto(out *BarbicanAPITemplateCore) {
 	in.BarbicanComponentTemplate.DeepCopyInto(&out.BarbicanComponentTemplate)
 	in.Override.DeepCopyInto(&out.Override)
 	in.TLS.DeepCopyInto(&out.TLS)
+	in.WSGI.DeepCopyInto(&out.WSGI)
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(BarbicanAutoscalingTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanAPITemplateCore.
@@ -259,6 +272,197 @@ func (in *BarbicanAPITemplateCore) DeepCopy() *BarbicanAPITemplateCore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanAPIWSGI) DeepCopyInto(out *BarbicanAPIWSGI) {
+	*out = *in
+	if in.Processes != nil {
+		in, out := &in.Processes, &out.Processes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaximumRequests != nil {
+		in, out := &in.MaximumRequests, &out.MaximumRequests
+		*out = new(int32)
+		**out = **in
+	}
+	if in.GracefulTimeout != nil {
+		in, out := &in.GracefulTimeout, &out.GracefulTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanAPIWSGI.
+func (in *BarbicanAPIWSGI) DeepCopy() *BarbicanAPIWSGI {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanAPIWSGI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanAPIWSGIStatus) DeepCopyInto(out *BarbicanAPIWSGIStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanAPIWSGIStatus.
+func (in *BarbicanAPIWSGIStatus) DeepCopy() *BarbicanAPIWSGIStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanAPIWSGIStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanAutoscalingTemplate) DeepCopyInto(out *BarbicanAutoscalingTemplate) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanAutoscalingTemplate.
+func (in *BarbicanAutoscalingTemplate) DeepCopy() *BarbicanAutoscalingTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanAutoscalingTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanBackup) DeepCopyInto(out *BarbicanBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanBackup.
+func (in *BarbicanBackup) DeepCopy() *BarbicanBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BarbicanBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanBackupList) DeepCopyInto(out *BarbicanBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BarbicanBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanBackupList.
+func (in *BarbicanBackupList) DeepCopy() *BarbicanBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BarbicanBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanBackupSpec) DeepCopyInto(out *BarbicanBackupSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanBackupSpec.
+func (in *BarbicanBackupSpec) DeepCopy() *BarbicanBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanBackupStatus) DeepCopyInto(out *BarbicanBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanBackupStatus.
+func (in *BarbicanBackupStatus) DeepCopy() *BarbicanBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BarbicanComponentTemplate) DeepCopyInto(out *BarbicanComponentTemplate) {
 	*out = *in
@@ -278,77 +482,547 @@ func (in *BarbicanComponentTemplate) DeepCopyInto(out *BarbicanComponentTemplate
 		*out = new(int32)
 		**out = **in
 	}
-	if in.DefaultConfigOverwrite != nil {
-		in, out := &in.DefaultConfigOverwrite, &out.DefaultConfigOverwrite
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.CustomServiceConfigSecrets != nil {
-		in, out := &in.CustomServiceConfigSecrets, &out.CustomServiceConfigSecrets
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.DefaultConfigOverwrite != nil {
+		in, out := &in.DefaultConfigOverwrite, &out.DefaultConfigOverwrite
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CustomServiceConfigSecrets != nil {
+		in, out := &in.CustomServiceConfigSecrets, &out.CustomServiceConfigSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(BarbicanLoggingTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NetworkAttachments != nil {
+		in, out := &in.NetworkAttachments, &out.NetworkAttachments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TopologyRef != nil {
+		in, out := &in.TopologyRef, &out.TopologyRef
+		*out = new(topologyv1beta1.TopoRef)
+		**out = **in
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(BarbicanPodDisruptionBudgetTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(BarbicanProbesTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(BarbicanRolloutStrategyTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanComponentTemplate.
+func (in *BarbicanComponentTemplate) DeepCopy() *BarbicanComponentTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanComponentTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanDBBackupStatus) DeepCopyInto(out *BarbicanDBBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanDBBackupStatus.
+func (in *BarbicanDBBackupStatus) DeepCopy() *BarbicanDBBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanDBBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanDBBackupTemplate) DeepCopyInto(out *BarbicanDBBackupTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanDBBackupTemplate.
+func (in *BarbicanDBBackupTemplate) DeepCopy() *BarbicanDBBackupTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanDBBackupTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanDBPurgeTemplate) DeepCopyInto(out *BarbicanDBPurgeTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanDBPurgeTemplate.
+func (in *BarbicanDBPurgeTemplate) DeepCopy() *BarbicanDBPurgeTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanDBPurgeTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanDatabaseSchemaStatus) DeepCopyInto(out *BarbicanDatabaseSchemaStatus) {
+	*out = *in
+	if in.SyncTime != nil {
+		in, out := &in.SyncTime, &out.SyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanDatabaseSchemaStatus.
+func (in *BarbicanDatabaseSchemaStatus) DeepCopy() *BarbicanDatabaseSchemaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanDatabaseSchemaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanDefaults) DeepCopyInto(out *BarbicanDefaults) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanDefaults.
+func (in *BarbicanDefaults) DeepCopy() *BarbicanDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanExternalDatabaseTemplate) DeepCopyInto(out *BarbicanExternalDatabaseTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanExternalDatabaseTemplate.
+func (in *BarbicanExternalDatabaseTemplate) DeepCopy() *BarbicanExternalDatabaseTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanExternalDatabaseTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKMIPTemplate) DeepCopyInto(out *BarbicanKMIPTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKMIPTemplate.
+func (in *BarbicanKMIPTemplate) DeepCopy() *BarbicanKMIPTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKMIPTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKeystoneListener) DeepCopyInto(out *BarbicanKeystoneListener) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListener.
+func (in *BarbicanKeystoneListener) DeepCopy() *BarbicanKeystoneListener {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKeystoneListener)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BarbicanKeystoneListener) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKeystoneListenerList) DeepCopyInto(out *BarbicanKeystoneListenerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BarbicanKeystoneListener, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerList.
+func (in *BarbicanKeystoneListenerList) DeepCopy() *BarbicanKeystoneListenerList {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKeystoneListenerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BarbicanKeystoneListenerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKeystoneListenerSpec) DeepCopyInto(out *BarbicanKeystoneListenerSpec) {
+	*out = *in
+	in.BarbicanTemplate.DeepCopyInto(&out.BarbicanTemplate)
+	in.BarbicanKeystoneListenerTemplate.DeepCopyInto(&out.BarbicanKeystoneListenerTemplate)
+	out.TLS = in.TLS
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerSpec.
+func (in *BarbicanKeystoneListenerSpec) DeepCopy() *BarbicanKeystoneListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKeystoneListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKeystoneListenerStatus) DeepCopyInto(out *BarbicanKeystoneListenerStatus) {
+	*out = *in
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkAttachments != nil {
+		in, out := &in.NetworkAttachments, &out.NetworkAttachments
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.LastAppliedTopology != nil {
+		in, out := &in.LastAppliedTopology, &out.LastAppliedTopology
+		*out = new(topologyv1beta1.TopoRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerStatus.
+func (in *BarbicanKeystoneListenerStatus) DeepCopy() *BarbicanKeystoneListenerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKeystoneListenerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKeystoneListenerTemplate) DeepCopyInto(out *BarbicanKeystoneListenerTemplate) {
+	*out = *in
+	in.BarbicanKeystoneListenerTemplateCore.DeepCopyInto(&out.BarbicanKeystoneListenerTemplateCore)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerTemplate.
+func (in *BarbicanKeystoneListenerTemplate) DeepCopy() *BarbicanKeystoneListenerTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKeystoneListenerTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanKeystoneListenerTemplateCore) DeepCopyInto(out *BarbicanKeystoneListenerTemplateCore) {
+	*out = *in
+	in.BarbicanComponentTemplate.DeepCopyInto(&out.BarbicanComponentTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerTemplateCore.
+func (in *BarbicanKeystoneListenerTemplateCore) DeepCopy() *BarbicanKeystoneListenerTemplateCore {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanKeystoneListenerTemplateCore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanList) DeepCopyInto(out *BarbicanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Barbican, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanList.
+func (in *BarbicanList) DeepCopy() *BarbicanList {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BarbicanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanLoggingTemplate) DeepCopyInto(out *BarbicanLoggingTemplate) {
+	*out = *in
+	if in.JSON != nil {
+		in, out := &in.JSON, &out.JSON
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ModuleLevels != nil {
+		in, out := &in.ModuleLevels, &out.ModuleLevels
+		*out = make(map[string]LogLevel, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanLoggingTemplate.
+func (in *BarbicanLoggingTemplate) DeepCopy() *BarbicanLoggingTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanLoggingTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanPKCS11Template) DeepCopyInto(out *BarbicanPKCS11Template) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SoftHSM != nil {
+		in, out := &in.SoftHSM, &out.SoftHSM
+		*out = new(BarbicanSoftHSMTemplate)
+		**out = **in
+	}
+	if in.SlotID != nil {
+		in, out := &in.SlotID, &out.SlotID
+		*out = new(int)
+		**out = **in
+	}
+	if in.AESGCMGenerateIV != nil {
+		in, out := &in.AESGCMGenerateIV, &out.AESGCMGenerateIV
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OSLockingOK != nil {
+		in, out := &in.OSLockingOK, &out.OSLockingOK
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanPKCS11Template.
+func (in *BarbicanPKCS11Template) DeepCopy() *BarbicanPKCS11Template {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanPKCS11Template)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanPodDisruptionBudgetTemplate) DeepCopyInto(out *BarbicanPodDisruptionBudgetTemplate) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanPodDisruptionBudgetTemplate.
+func (in *BarbicanPodDisruptionBudgetTemplate) DeepCopy() *BarbicanPodDisruptionBudgetTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanPodDisruptionBudgetTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanProbeTemplate) DeepCopyInto(out *BarbicanProbeTemplate) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.NetworkAttachments != nil {
-		in, out := &in.NetworkAttachments, &out.NetworkAttachments
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
 	}
-	if in.TopologyRef != nil {
-		in, out := &in.TopologyRef, &out.TopologyRef
-		*out = new(topologyv1beta1.TopoRef)
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanComponentTemplate.
-func (in *BarbicanComponentTemplate) DeepCopy() *BarbicanComponentTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanProbeTemplate.
+func (in *BarbicanProbeTemplate) DeepCopy() *BarbicanProbeTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanComponentTemplate)
+	out := new(BarbicanProbeTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanDefaults) DeepCopyInto(out *BarbicanDefaults) {
+func (in *BarbicanProbesTemplate) DeepCopyInto(out *BarbicanProbesTemplate) {
 	*out = *in
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(BarbicanProbeTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(BarbicanProbeTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(BarbicanProbeTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanDefaults.
-func (in *BarbicanDefaults) DeepCopy() *BarbicanDefaults {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanProbesTemplate.
+func (in *BarbicanProbesTemplate) DeepCopy() *BarbicanProbesTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanDefaults)
+	out := new(BarbicanProbesTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanKeystoneListener) DeepCopyInto(out *BarbicanKeystoneListener) {
+func (in *BarbicanRestore) DeepCopyInto(out *BarbicanRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListener.
-func (in *BarbicanKeystoneListener) DeepCopy() *BarbicanKeystoneListener {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanRestore.
+func (in *BarbicanRestore) DeepCopy() *BarbicanRestore {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanKeystoneListener)
+	out := new(BarbicanRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *BarbicanKeystoneListener) DeepCopyObject() runtime.Object {
+func (in *BarbicanRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -356,31 +1030,31 @@ func (in *BarbicanKeystoneListener) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanKeystoneListenerList) DeepCopyInto(out *BarbicanKeystoneListenerList) {
+func (in *BarbicanRestoreList) DeepCopyInto(out *BarbicanRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]BarbicanKeystoneListener, len(*in))
+		*out = make([]BarbicanRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerList.
-func (in *BarbicanKeystoneListenerList) DeepCopy() *BarbicanKeystoneListenerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanRestoreList.
+func (in *BarbicanRestoreList) DeepCopy() *BarbicanRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanKeystoneListenerList)
+	out := new(BarbicanRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *BarbicanKeystoneListenerList) DeepCopyObject() runtime.Object {
+func (in *BarbicanRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -388,26 +1062,31 @@ func (in *BarbicanKeystoneListenerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanKeystoneListenerSpec) DeepCopyInto(out *BarbicanKeystoneListenerSpec) {
+func (in *BarbicanRestoreSpec) DeepCopyInto(out *BarbicanRestoreSpec) {
 	*out = *in
-	in.BarbicanTemplate.DeepCopyInto(&out.BarbicanTemplate)
-	in.BarbicanKeystoneListenerTemplate.DeepCopyInto(&out.BarbicanKeystoneListenerTemplate)
-	out.TLS = in.TLS
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerSpec.
-func (in *BarbicanKeystoneListenerSpec) DeepCopy() *BarbicanKeystoneListenerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanRestoreSpec.
+func (in *BarbicanRestoreSpec) DeepCopy() *BarbicanRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanKeystoneListenerSpec)
+	out := new(BarbicanRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanKeystoneListenerStatus) DeepCopyInto(out *BarbicanKeystoneListenerStatus) {
+func (in *BarbicanRestoreStatus) DeepCopyInto(out *BarbicanRestoreStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Hash != nil {
 		in, out := &in.Hash, &out.Hash
 		*out = make(map[string]string, len(*in))
@@ -422,114 +1101,79 @@ func (in *BarbicanKeystoneListenerStatus) DeepCopyInto(out *BarbicanKeystoneList
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.NetworkAttachments != nil {
-		in, out := &in.NetworkAttachments, &out.NetworkAttachments
-		*out = make(map[string][]string, len(*in))
-		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
-	}
-	if in.LastAppliedTopology != nil {
-		in, out := &in.LastAppliedTopology, &out.LastAppliedTopology
-		*out = new(topologyv1beta1.TopoRef)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerStatus.
-func (in *BarbicanKeystoneListenerStatus) DeepCopy() *BarbicanKeystoneListenerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanRestoreStatus.
+func (in *BarbicanRestoreStatus) DeepCopy() *BarbicanRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanKeystoneListenerStatus)
+	out := new(BarbicanRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanKeystoneListenerTemplate) DeepCopyInto(out *BarbicanKeystoneListenerTemplate) {
+func (in *BarbicanRolloutStrategyTemplate) DeepCopyInto(out *BarbicanRolloutStrategyTemplate) {
 	*out = *in
-	in.BarbicanKeystoneListenerTemplateCore.DeepCopyInto(&out.BarbicanKeystoneListenerTemplateCore)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerTemplate.
-func (in *BarbicanKeystoneListenerTemplate) DeepCopy() *BarbicanKeystoneListenerTemplate {
-	if in == nil {
-		return nil
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MinReadySeconds != nil {
+		in, out := &in.MinReadySeconds, &out.MinReadySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
 	}
-	out := new(BarbicanKeystoneListenerTemplate)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanKeystoneListenerTemplateCore) DeepCopyInto(out *BarbicanKeystoneListenerTemplateCore) {
-	*out = *in
-	in.BarbicanComponentTemplate.DeepCopyInto(&out.BarbicanComponentTemplate)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanKeystoneListenerTemplateCore.
-func (in *BarbicanKeystoneListenerTemplateCore) DeepCopy() *BarbicanKeystoneListenerTemplateCore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanRolloutStrategyTemplate.
+func (in *BarbicanRolloutStrategyTemplate) DeepCopy() *BarbicanRolloutStrategyTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanKeystoneListenerTemplateCore)
+	out := new(BarbicanRolloutStrategyTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanList) DeepCopyInto(out *BarbicanList) {
+func (in *BarbicanSecretStoreMigrationTemplate) DeepCopyInto(out *BarbicanSecretStoreMigrationTemplate) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Barbican, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanList.
-func (in *BarbicanList) DeepCopy() *BarbicanList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanSecretStoreMigrationTemplate.
+func (in *BarbicanSecretStoreMigrationTemplate) DeepCopy() *BarbicanSecretStoreMigrationTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanList)
+	out := new(BarbicanSecretStoreMigrationTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *BarbicanList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BarbicanPKCS11Template) DeepCopyInto(out *BarbicanPKCS11Template) {
+func (in *BarbicanSoftHSMTemplate) DeepCopyInto(out *BarbicanSoftHSMTemplate) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanPKCS11Template.
-func (in *BarbicanPKCS11Template) DeepCopy() *BarbicanPKCS11Template {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanSoftHSMTemplate.
+func (in *BarbicanSoftHSMTemplate) DeepCopy() *BarbicanSoftHSMTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(BarbicanPKCS11Template)
+	out := new(BarbicanSoftHSMTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -575,12 +1219,39 @@ func (in *BarbicanSpecBase) DeepCopyInto(out *BarbicanSpecBase) {
 			(*out)[key] = val
 		}
 	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(BarbicanLoggingTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretStoreMigration != nil {
+		in, out := &in.SecretStoreMigration, &out.SecretStoreMigration
+		*out = new(BarbicanSecretStoreMigrationTemplate)
+		**out = **in
+	}
+	if in.ProjectSecretStores != nil {
+		in, out := &in.ProjectSecretStores, &out.ProjectSecretStores
+		*out = make(map[string]SecretStore, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	out.Auth = in.Auth
 	if in.TopologyRef != nil {
 		in, out := &in.TopologyRef, &out.TopologyRef
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	if in.DBPurge != nil {
+		in, out := &in.DBPurge, &out.DBPurge
+		*out = new(BarbicanDBPurgeTemplate)
+		**out = **in
+	}
+	if in.DBBackup != nil {
+		in, out := &in.DBBackup, &out.DBBackup
+		*out = new(BarbicanDBBackupTemplate)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanSpecBase.
@@ -634,6 +1305,47 @@ func (in *BarbicanStatus) DeepCopyInto(out *BarbicanStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SimpleCryptoKEKGenerationTime != nil {
+		in, out := &in.SimpleCryptoKEKGenerationTime, &out.SimpleCryptoKEKGenerationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SimpleCryptoKEKRotation != nil {
+		in, out := &in.SimpleCryptoKEKRotation, &out.SimpleCryptoKEKRotation
+		*out = new(SimpleCryptoKEKRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PKCS11KeyRotation != nil {
+		in, out := &in.PKCS11KeyRotation, &out.PKCS11KeyRotation
+		*out = new(PKCS11KeyRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretStoreMigration != nil {
+		in, out := &in.SecretStoreMigration, &out.SecretStoreMigration
+		*out = new(SecretStoreMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProjectSecretStores != nil {
+		in, out := &in.ProjectSecretStores, &out.ProjectSecretStores
+		*out = make(map[string]ProjectSecretStoreStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Update != nil {
+		in, out := &in.Update, &out.Update
+		*out = new(BarbicanUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseSchema != nil {
+		in, out := &in.DatabaseSchema, &out.DatabaseSchema
+		*out = new(BarbicanDatabaseSchemaStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DBBackup != nil {
+		in, out := &in.DBBackup, &out.DBBackup
+		*out = new(BarbicanDBBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanStatus.
@@ -649,6 +1361,11 @@ func (in *BarbicanStatus) DeepCopy() *BarbicanStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BarbicanTemplate) DeepCopyInto(out *BarbicanTemplate) {
 	*out = *in
+	if in.ExternalDatabase != nil {
+		in, out := &in.ExternalDatabase, &out.ExternalDatabase
+		*out = new(BarbicanExternalDatabaseTemplate)
+		**out = **in
+	}
 	out.MessagingBus = in.MessagingBus
 	if in.NotificationsBus != nil {
 		in, out := &in.NotificationsBus, &out.NotificationsBus
@@ -659,6 +1376,16 @@ func (in *BarbicanTemplate) DeepCopyInto(out *BarbicanTemplate) {
 	if in.PKCS11 != nil {
 		in, out := &in.PKCS11, &out.PKCS11
 		*out = new(BarbicanPKCS11Template)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KMIP != nil {
+		in, out := &in.KMIP, &out.KMIP
+		*out = new(BarbicanKMIPTemplate)
+		**out = **in
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(BarbicanVaultTemplate)
 		**out = **in
 	}
 	if in.EnabledSecretStores != nil {
@@ -666,6 +1393,13 @@ func (in *BarbicanTemplate) DeepCopyInto(out *BarbicanTemplate) {
 		*out = make([]SecretStore, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExtraMounts != nil {
+		in, out := &in.ExtraMounts, &out.ExtraMounts
+		*out = make([]storage.VolMounts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanTemplate.
@@ -678,6 +1412,61 @@ func (in *BarbicanTemplate) DeepCopy() *BarbicanTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanUpdateImages) DeepCopyInto(out *BarbicanUpdateImages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanUpdateImages.
+func (in *BarbicanUpdateImages) DeepCopy() *BarbicanUpdateImages {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanUpdateImages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanUpdateStatus) DeepCopyInto(out *BarbicanUpdateStatus) {
+	*out = *in
+	out.TargetImages = in.TargetImages
+	out.PreviousImages = in.PreviousImages
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanUpdateStatus.
+func (in *BarbicanUpdateStatus) DeepCopy() *BarbicanUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BarbicanVaultTemplate) DeepCopyInto(out *BarbicanVaultTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanVaultTemplate.
+func (in *BarbicanVaultTemplate) DeepCopy() *BarbicanVaultTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BarbicanVaultTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BarbicanWorker) DeepCopyInto(out *BarbicanWorker) {
 	*out = *in
@@ -825,6 +1614,11 @@ func (in *BarbicanWorkerTemplate) DeepCopy() *BarbicanWorkerTemplate {
 func (in *BarbicanWorkerTemplateCore) DeepCopyInto(out *BarbicanWorkerTemplateCore) {
 	*out = *in
 	in.BarbicanComponentTemplate.DeepCopyInto(&out.BarbicanComponentTemplate)
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(BarbicanAutoscalingTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarbicanWorkerTemplateCore.
@@ -837,6 +1631,25 @@ func (in *BarbicanWorkerTemplateCore) DeepCopy() *BarbicanWorkerTemplateCore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKCS11KeyRotationStatus) DeepCopyInto(out *PKCS11KeyRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKCS11KeyRotationStatus.
+func (in *PKCS11KeyRotationStatus) DeepCopy() *PKCS11KeyRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PKCS11KeyRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PasswordSelector) DeepCopyInto(out *PasswordSelector) {
 	*out = *in
@@ -856,3 +1669,73 @@ func (in *PasswordSelector) DeepCopy() *PasswordSelector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectSecretStoreStatus) DeepCopyInto(out *ProjectSecretStoreStatus) {
+	*out = *in
+	if in.LastDriftTime != nil {
+		in, out := &in.LastDriftTime, &out.LastDriftTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectSecretStoreStatus.
+func (in *ProjectSecretStoreStatus) DeepCopy() *ProjectSecretStoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectSecretStoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretStoreMigrationStatus) DeepCopyInto(out *SecretStoreMigrationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStoreMigrationStatus.
+func (in *SecretStoreMigrationStatus) DeepCopy() *SecretStoreMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretStoreMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SimpleCryptoKEKRotationStatus) DeepCopyInto(out *SimpleCryptoKEKRotationStatus) {
+	*out = *in
+	if in.RetiredKEKs != nil {
+		in, out := &in.RetiredKEKs, &out.RetiredKEKs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimpleCryptoKEKRotationStatus.
+func (in *SimpleCryptoKEKRotationStatus) DeepCopy() *SimpleCryptoKEKRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SimpleCryptoKEKRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -279,6 +279,22 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "BarbicanKeystoneListener")
 		os.Exit(1)
 	}
+	if err := (&controller.BarbicanBackupReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Kclient: kclient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BarbicanBackup")
+		os.Exit(1)
+	}
+	if err := (&controller.BarbicanRestoreReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Kclient: kclient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BarbicanRestore")
+		os.Exit(1)
+	}
 
 	barbicanv1beta1.SetupDefaults()
 
@@ -0,0 +1,284 @@
+package barbican
+
+import (
+	"fmt"
+	"time"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackupKEKFileName - file of the bundle holding the encrypted KEK Secret
+	BackupKEKFileName = "kek.enc"
+
+	// BackupDumpFileName - file of the bundle holding the database dump
+	BackupDumpFileName = "barbican.sql.gz"
+
+	// RestoreNoKEKKey - field the KEK restore Job sets in the restore Secret
+	// when the bundle has no encrypted KEKs
+	RestoreNoKEKKey = "none"
+
+	// serviceAccountDir - directory of the service account token of the pods
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// backupBundleMountPoint - directory the PersistentVolumeClaim of the
+	// bundles is mounted at
+	backupBundleMountPoint = "/backup"
+
+	// backupConfigMountPoint - directory the rendered config is copied from
+	backupConfigMountPoint = "/var/lib/config-data/barbican"
+
+	// backupKEKMountPoint - directory the encrypted KEK Secret is copied from
+	backupKEKMountPoint = "/var/lib/config-data/kek"
+
+	// backupScript writes the database dump, the rendered config and the
+	// encrypted KEK Secret to the bundle. The bundle is only renamed once
+	// complete, and is only readable by the barbican user as the config and
+	// the dump hold credentials.
+	backupScript = `set -euo pipefail
+umask 077
+bundle=` + backupBundleMountPoint + `/${BACKUP_BUNDLE}
+rm -rf "${bundle}.part"
+mkdir -p "${bundle}.part/config"
+` + dbDumpCommand + ` | gzip > "${bundle}.part/` + BackupDumpFileName + `"
+cp -L ` + backupConfigMountPoint + `/* "${bundle}.part/config/"
+if [ -f ` + backupKEKMountPoint + `/` + BackupKEKFileName + ` ]; then
+    cp -L ` + backupKEKMountPoint + `/` + BackupKEKFileName + ` "${bundle}.part/"
+fi
+rm -rf "${bundle}"
+mv "${bundle}.part" "${bundle}"
+`
+
+	// restoreKEKScript copies the encrypted KEKs of the bundle to the restore
+	// Secret, the operator decrypts them. The KEKs do not fit in a termination
+	// message, and the operator cannot mount the bundle.
+	restoreKEKScript = `set -euo pipefail
+bundle=` + backupBundleMountPoint + `/${BACKUP_BUNDLE}
+[ -d "${bundle}" ] || { echo "bundle ${BACKUP_BUNDLE} not found" >&2; exit 1; }
+if [ -f "${bundle}/` + BackupKEKFileName + `" ]; then
+    field=` + BackupKEKFileName + `
+    value=$(base64 -w0 "${bundle}/` + BackupKEKFileName + `")
+else
+    field=` + RestoreNoKEKKey + `
+    value=""
+fi
+printf '{"data":{"%s":"%s"}}' "${field}" "${value}" | \
+    curl -sSf -X PATCH --data-binary @- \
+    --cacert ` + serviceAccountDir + `/ca.crt \
+    -H "Authorization: Bearer $(cat ` + serviceAccountDir + `/token)" \
+    -H "Content-Type: application/merge-patch+json" \
+    "https://kubernetes.default.svc/api/v1/namespaces/${RESTORE_NAMESPACE}/secrets/${RESTORE_KEK_SECRET}" > /dev/null
+`
+
+	// restoreDBScript loads the database dump of the bundle
+	restoreDBScript = `set -euo pipefail
+gunzip -c "` + backupBundleMountPoint + `/${BACKUP_BUNDLE}/` + BackupDumpFileName + `" | \
+    mysql --defaults-extra-file=/etc/my.cnf -h "${DB_HOST}" -u "${DB_USER}" "${DB_NAME}"
+`
+)
+
+// GetBackupBundleName - returns the name of the bundle of the backup started
+// at the given time
+func GetBackupBundleName(name string, t time.Time) string {
+	return fmt.Sprintf("%s-%s", name, t.UTC().Format(dbBackupTimeFormat))
+}
+
+// GetBackupKEKSecretName - returns the name of the Secret holding the KEK
+// Secret encrypted for the backup
+func GetBackupKEKSecretName(name string) string {
+	return name + "-kek"
+}
+
+// GetRestoreKEKSecretName - returns the name of the Secret the KEK restore Job
+// copies the encrypted KEKs of the bundle to
+func GetRestoreKEKSecretName(name string) string {
+	return name + "-restore-kek"
+}
+
+// BackupJob - writes the bundle of the backup: the dump of the database of the
+// Barbican, its rendered config and its encrypted KEK Secret
+func BackupJob(
+	backup *barbicanv1beta1.BarbicanBackup,
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	databaseAccount *mariadbv1.MariaDBAccount,
+) *batchv1.Job {
+	name := backup.Name + "-backup"
+
+	var config0644AccessMode int32 = 0644
+	backupVolumes, backupMounts := getDatabaseClientVolumes(instance)
+	backupVolumes = append(backupVolumes,
+		getBundleVolume(backup.Spec.PersistentVolumeClaim, false),
+		corev1.Volume{
+			Name: "backup-config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &config0644AccessMode,
+					SecretName:  instance.Name + "-config-data",
+				},
+			},
+		},
+	)
+	backupMounts = append(backupMounts,
+		getBundleVolumeMount(false),
+		corev1.VolumeMount{
+			Name:      "backup-config-data",
+			MountPath: backupConfigMountPoint,
+			ReadOnly:  true,
+		},
+	)
+
+	// add the encrypted KEK Secret
+	if backup.Status.KEKSecret != "" {
+		backupVolumes = append(backupVolumes, corev1.Volume{
+			Name: "backup-kek",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &config0644AccessMode,
+					SecretName:  GetBackupKEKSecretName(backup.Name),
+				},
+			},
+		})
+		backupMounts = append(backupMounts, corev1.VolumeMount{
+			Name:      "backup-kek",
+			MountPath: backupKEKMountPoint,
+			ReadOnly:  true,
+		})
+	}
+
+	secretEnv, envVars := getDatabaseClientEnv(instance, databaseAccount)
+	envVars["BACKUP_BUNDLE"] = env.SetValue(backup.Status.Bundle)
+	// the KEK encrypted in the bundle is part of the job hash, so the bundle
+	// is written again when the KEK changed while the dump was running
+	envVars["BACKUP_KEK_HASH"] = env.SetValue(backup.Status.KEKHash)
+
+	return backupRestoreJob(name, backup.Namespace, backup.Spec.ContainerImage, instance, labels,
+		backupScript, backupVolumes, backupMounts, env.MergeEnvs(secretEnv, envVars))
+}
+
+// RestoreKEKJob - copies the encrypted KEKs of the bundle of the restore to
+// the restore Secret. The Job runs with the service account of the restore,
+// which may only patch that Secret.
+func RestoreKEKJob(
+	restore *barbicanv1beta1.BarbicanRestore,
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+) *batchv1.Job {
+	envVars := map[string]env.Setter{}
+	envVars["BACKUP_BUNDLE"] = env.SetValue(restore.Spec.Bundle)
+	envVars["RESTORE_NAMESPACE"] = env.SetValue(restore.Namespace)
+	envVars["RESTORE_KEK_SECRET"] = env.SetValue(GetRestoreKEKSecretName(restore.Name))
+
+	job := backupRestoreJob(restore.Name+"-restore-kek", restore.Namespace, restore.Spec.ContainerImage, instance, labels,
+		restoreKEKScript,
+		[]corev1.Volume{getBundleVolume(restore.Spec.PersistentVolumeClaim, true)},
+		[]corev1.VolumeMount{getBundleVolumeMount(true)},
+		env.MergeEnvs([]corev1.EnvVar{}, envVars))
+	job.Spec.Template.Spec.ServiceAccountName = restore.RbacResourceName()
+	return job
+}
+
+// RestoreDBJob - loads the database dump of the bundle of the restore into the
+// database of the Barbican
+func RestoreDBJob(
+	restore *barbicanv1beta1.BarbicanRestore,
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	databaseAccount *mariadbv1.MariaDBAccount,
+) *batchv1.Job {
+	restoreVolumes, restoreMounts := getDatabaseClientVolumes(instance)
+	restoreVolumes = append(restoreVolumes, getBundleVolume(restore.Spec.PersistentVolumeClaim, true))
+	restoreMounts = append(restoreMounts, getBundleVolumeMount(true))
+
+	secretEnv, envVars := getDatabaseClientEnv(instance, databaseAccount)
+	envVars["BACKUP_BUNDLE"] = env.SetValue(restore.Spec.Bundle)
+
+	return backupRestoreJob(restore.Name+"-restore-db", restore.Namespace, restore.Spec.ContainerImage, instance, labels,
+		restoreDBScript, restoreVolumes, restoreMounts, env.MergeEnvs(secretEnv, envVars))
+}
+
+func getBundleVolume(claimName string, readOnly bool) corev1.Volume {
+	return corev1.Volume{
+		Name: "backup",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+				ReadOnly:  readOnly,
+			},
+		},
+	}
+}
+
+func getBundleVolumeMount(readOnly bool) corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "backup",
+		MountPath: backupBundleMountPoint,
+		ReadOnly:  readOnly,
+	}
+}
+
+// backupRestoreJob - the Job running the script with the service account of
+// the Barbican and as the barbican user, who owns the bundles. The images of
+// the Barbican do not ship the mysql client, the MariaDB image is used when
+// none is given.
+func backupRestoreJob(
+	name string,
+	namespace string,
+	image string,
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	script string,
+	volumes []corev1.Volume,
+	mounts []corev1.VolumeMount,
+	envs []corev1.EnvVar,
+) *batchv1.Job {
+	if image == "" {
+		image = barbicanv1beta1.GetDBClientContainerImage()
+	}
+	fsGroup := BarbicanGID
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: instance.RbacResourceName(),
+					SecurityContext: &corev1.PodSecurityContext{
+						FSGroup: &fsGroup,
+					},
+					Volumes: volumes,
+					Containers: []corev1.Container{
+						{
+							Name: name,
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:            []string{"-c", script},
+							Image:           image,
+							SecurityContext: GetBaseSecurityContext(),
+							Env:             envs,
+							VolumeMounts:    mounts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+
+	return job
+}
@@ -0,0 +1,132 @@
+package barbican
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Static errors for the encryption of the KEK Secret
+var (
+	ErrBackupKeyNotPEM = errors.New("key is not PEM encoded")
+	ErrBackupKeyNotRSA = errors.New("key is not an RSA key")
+)
+
+// kekEnvelope - the data of the KEK Secret encrypted to an RSA public key.
+// The data is sealed with a random AES-256-GCM key, which is encrypted with
+// RSA-OAEP and SHA-256, as RSA alone only encrypts a few bytes.
+type kekEnvelope struct {
+	Key   []byte `json:"key"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// EncryptKEKSecret - returns the data of the KEK Secret encrypted to the PEM
+// encoded RSA public key, in the format DecryptKEKSecret reads
+func EncryptKEKSecret(data map[string][]byte, publicKeyPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, ErrBackupKeyNotPEM
+	}
+	var publicKey *rsa.PublicKey
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, ErrBackupKeyNotRSA
+		}
+		publicKey = rsaKey
+	} else if publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("error parsing public key: %w", err)
+	}
+
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	gcm, err := newKEKEnvelopeCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting the data key: %w", err)
+	}
+
+	return json.Marshal(kekEnvelope{
+		Key:   encryptedKey,
+		Nonce: nonce,
+		Data:  gcm.Seal(nil, nonce, plaintext, nil),
+	})
+}
+
+// DecryptKEKSecret - returns the data of the KEK Secret encrypted by
+// EncryptKEKSecret, decrypted with the PEM encoded RSA private key
+func DecryptKEKSecret(encrypted []byte, privateKeyPEM []byte) (map[string][]byte, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, ErrBackupKeyNotPEM
+	}
+	var privateKey *rsa.PrivateKey
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrBackupKeyNotRSA
+		}
+		privateKey = rsaKey
+	} else if privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+
+	envelope := kekEnvelope{}
+	if err := json.Unmarshal(encrypted, &envelope); err != nil {
+		return nil, fmt.Errorf("error reading the encrypted KEK Secret: %w", err)
+	}
+
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, envelope.Key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting the data key: %w", err)
+	}
+	gcm, err := newKEKEnvelopeCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	// Open panics on a nonce of the wrong size
+	if len(envelope.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("error decrypting the KEK Secret: invalid nonce size %d", len(envelope.Nonce))
+	}
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting the KEK Secret: %w", err)
+	}
+
+	data := map[string][]byte{}
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func newKEKEnvelopeCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -37,6 +37,10 @@ const (
 	CustomServiceConfigFileName = "02-service-custom.conf"
 	// CustomServiceConfigSecretsFileName -
 	CustomServiceConfigSecretsFileName = "03-secrets-custom.conf" // #nosec G101
+	// WSGIConfigFileName - the httpd config holding the mod_wsgi tuning of the API
+	WSGIConfigFileName = "10-barbican_wsgi_main.conf"
+	// LoggingConfigFileName - the defaultConfigOverwrite file used as log_config_append
+	LoggingConfigFileName = "logging.conf"
 	// BarbicanAPI defines the barbican-api group
 	BarbicanAPI storage.PropagationType = "BarbicanAPI"
 	// BarbicanWorker defines the barbican-worker group
@@ -58,6 +62,8 @@ const (
 	CustomConfigVolume = "config-data-custom"
 	// CustomConfigMountPoint is the mount point for custom service config
 	CustomConfigMountPoint = "/etc/barbican/barbican.conf.d"
+	// ConfigOverwriteDir is the directory kolla copies the defaultConfigOverwrite files to
+	ConfigOverwriteDir = "/etc/barbican"
 	// ScriptVolume is the default volume name used to mount scripts
 	ScriptVolume = "scripts"
 	// ScriptMountPoint is the mount point for scripts
@@ -66,6 +72,47 @@ const (
 	PKCS11ClientDataVolume = "pkcs11-client-data"
 	// PKCS11ClientDataMountPoint is the mount point used for PKCS11 client Data
 	PKCS11ClientDataMountPoint = "/var/lib/config-data/hsm"
+	// SoftHSMTokenVolume is the volume used to mount the SoftHSM token store
+	SoftHSMTokenVolume = "softhsm-tokens"
+	// SoftHSMTokenMountPoint is the mount point used for the SoftHSM token store
+	SoftHSMTokenMountPoint = "/var/lib/softhsm/tokens"
+	// SoftHSMDefaultTokenLabel is the label of the SoftHSM token when no tokenLabel is set
+	SoftHSMDefaultTokenLabel = "barbican"
+	// SoftHSMSOPinKey is the key holding the SoftHSM security officer PIN in the LoginSecret
+	SoftHSMSOPinKey = "SoftHSMSOPin" // #nosec G101
+	// SoftHSMPinLength is the length in bytes of the generated SoftHSM PINs
+	SoftHSMPinLength = 16
+	// KMIPClientDataVolume is the volume used to mount the KMIP client certificates
+	KMIPClientDataVolume = "kmip-client-data"
+	// KMIPClientDataMountPoint is the mount point used for the KMIP client certificates
+	KMIPClientDataMountPoint = "/var/lib/config-data/kmip"
+	// KMIPClientDataPath is the location to which kolla copies the KMIP client certificates
+	KMIPClientDataPath = "/etc/barbican/kmip"
+	// KMIPClientCertKey is the key holding the KMIP client certificate in the ClientCertSecret
+	KMIPClientCertKey = "tls.crt"
+	// KMIPClientKeyKey is the key holding the KMIP client private key in the ClientCertSecret
+	KMIPClientKeyKey = "tls.key"
+	// KMIPCAKey is the key holding the KMIP server CA certificate in the CASecret
+	KMIPCAKey = "ca.crt"
+	// SimpleCryptoKEKGeneratedAnnotation is set on a SimpleCryptoBackendSecret
+	// generated by the operator and records when the KEK was generated
+	SimpleCryptoKEKGeneratedAnnotation = "barbican.openstack.org/simple-crypto-kek-generated"
+	// SimpleCryptoKEKLength is the length in bytes of a generated Simple Crypto KEK
+	SimpleCryptoKEKLength = 32
+	// VaultCAVolume is the volume used to mount the Vault CA certificate
+	VaultCAVolume = "vault-ca"
+	// VaultCAMountPoint is the mount point used for the Vault CA certificate
+	VaultCAMountPoint = "/var/lib/config-data/vault"
+	// VaultCAPath is the location to which kolla copies the Vault CA certificate
+	VaultCAPath = "/etc/barbican/vault"
+	// VaultCAKey is the key holding the Vault CA certificate in the CASecret
+	VaultCAKey = "ca.crt"
+	// VaultTokenKey is the key holding the Vault token in the AuthSecret
+	VaultTokenKey = "token"
+	// VaultRoleIDKey is the key holding the AppRole role ID in the AuthSecret
+	VaultRoleIDKey = "roleID"
+	// VaultSecretIDKey is the key holding the AppRole secret ID in the AuthSecret
+	VaultSecretIDKey = "secretID"
 	// BarbicanUID - based on https://github.com/openstack-k8s-operators/tcib/blob/main/container-images/kolla/base/uid_gid_manage.sh
 	BarbicanUID int64 = 42403
 	// BarbicanGID - based on https://github.com/openstack-k8s-operators/tcib/blob/main/container-images/kolla/base/uid_gid_manage.sh
@@ -0,0 +1,42 @@
+package barbican
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+)
+
+// GetPostgreSQLDatabaseConnection - returns the SQLAlchemy connection to the
+// externally managed PostgreSQL DB of the Barbican. The credentials are
+// escaped, they may hold any character: the URL escapes the reserved ones,
+// and $ is doubled as oslo.config interpolates it.
+func GetPostgreSQLDatabaseConnection(
+	instance *barbicanv1beta1.Barbican,
+	username string,
+	password string,
+) string {
+	externalDB := instance.Spec.ExternalDatabase
+
+	query := url.Values{}
+	if externalDB.SSLMode != "" {
+		query.Set("sslmode", externalDB.SSLMode)
+	}
+	// the CA bundle is mounted in the services and the jobs
+	if instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
+		query.Set("sslrootcert", tls.DownstreamTLSCABundlePath)
+	}
+
+	connection := url.URL{
+		Scheme:   "postgresql+psycopg2",
+		User:     url.UserPassword(username, password),
+		Host:     net.JoinHostPort(externalDB.Host, strconv.Itoa(int(externalDB.Port))),
+		Path:     "/" + externalDB.Name,
+		RawQuery: query.Encode(),
+	}
+	return strings.ReplaceAll(connection.String(), "$", "$$")
+}
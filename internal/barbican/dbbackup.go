@@ -0,0 +1,260 @@
+package barbican
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DBBackupMountPoint - directory the dumps are written to
+	DBBackupMountPoint = "/backup"
+
+	// The keys of the Secret of the S3 compatible object store the dumps are
+	// uploaded to
+	DBBackupObjectStoreEndpointKey        = "endpoint"
+	DBBackupObjectStoreBucketKey          = "bucket"
+	DBBackupObjectStoreRegionKey          = "region"
+	DBBackupObjectStoreAccessKeyIDKey     = "accessKeyID"
+	DBBackupObjectStoreSecretAccessKeyKey = "secretAccessKey"
+
+	// dbBackupTimeFormat - format of the time in the name of the dumps, the
+	// dumps of an instance sort by name in the order they were taken
+	dbBackupTimeFormat = "20060102150405"
+
+	// dbDumpCommand dumps the database with the client config of the
+	// services in /etc/my.cnf
+	dbDumpCommand = `mysqldump --defaults-extra-file=/etc/my.cnf --single-transaction --routines --triggers \
+    -h "${DB_HOST}" -u "${DB_USER}" "${DB_NAME}"`
+
+	// dbBackupScript dumps the database. The dump is only renamed once
+	// complete, so a failed dump is never mistaken for a good one.
+	dbBackupScript = `set -euo pipefail
+dump=` + DBBackupMountPoint + `/${DB_BACKUP_DUMP}
+` + dbDumpCommand + ` | gzip > "${dump}.part"
+mv "${dump}.part" "${dump}"
+dumps() { grep -E "^${DB_BACKUP_PREFIX}-[0-9]{14}\.sql\.gz$" | sort -r | tail -n +$((DB_BACKUP_RETENTION + 1)) || true; }
+`
+
+	// dbBackupVolumeScript keeps the latest dumps in the volume
+	dbBackupVolumeScript = dbBackupScript + `ls -1 ` + DBBackupMountPoint + ` | dumps | while read -r old; do
+    rm -f "` + DBBackupMountPoint + `/${old}"
+done
+`
+
+	// dbBackupObjectStoreScript uploads the dump to the bucket and keeps the
+	// latest dumps in it
+	dbBackupObjectStoreScript = dbBackupScript + `s3() {
+    curl -sSf --aws-sigv4 "aws:amz:${S3_REGION}:s3" --user "${S3_ACCESS_KEY_ID}:${S3_SECRET_ACCESS_KEY}" "$@"
+}
+s3 -T "${dump}" "${S3_ENDPOINT}/${S3_BUCKET}/${DB_BACKUP_DUMP}"
+rm -f "${dump}"
+s3 "${S3_ENDPOINT}/${S3_BUCKET}?list-type=2&prefix=${DB_BACKUP_PREFIX}-" | \
+    { grep -o '<Key>[^<]*</Key>' || true; } | sed -e 's/<[^>]*>//g' | dumps | while read -r old; do
+    s3 -X DELETE "${S3_ENDPOINT}/${S3_BUCKET}/${old}"
+done
+`
+)
+
+// GetDBBackupDumpName - returns the name of the dump of the instance taken at
+// the given time
+func GetDBBackupDumpName(name string, t time.Time) string {
+	return fmt.Sprintf("%s-%s.sql.gz", name, t.UTC().Format(dbBackupTimeFormat))
+}
+
+// GetDBBackupJobName - returns the name of the Job dumping the database of
+// the instance
+func GetDBBackupJobName(name string) string {
+	return name + "-db-backup"
+}
+
+// DBBackupJob - dumps the barbican database to the PersistentVolumeClaim or
+// the object store of the spec, and prunes the dumps beyond the retention
+func DBBackupJob(
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	annotations map[string]string,
+	backup *barbicanv1beta1.BarbicanDBBackupStatus,
+	databaseAccount *mariadbv1.MariaDBAccount,
+) *batchv1.Job {
+	name := GetDBBackupJobName(instance.Name)
+	dbBackup := instance.Spec.DBBackup
+
+	backupVolumes, backupMounts := getDatabaseClientVolumes(instance)
+	backupMounts = append(backupMounts, corev1.VolumeMount{
+		Name:      "db-backup",
+		MountPath: DBBackupMountPoint,
+	})
+
+	secretEnv, envVars := getDatabaseClientEnv(instance, databaseAccount)
+	envVars["DB_BACKUP_PREFIX"] = env.SetValue(instance.Name)
+	envVars["DB_BACKUP_RETENTION"] = env.SetValue(strconv.Itoa(dbBackup.Retention))
+	// the name of the dump is part of the job hash, so every backup runs a
+	// new job
+	envVars["DB_BACKUP_DUMP"] = env.SetValue(backup.Dump)
+
+	script := dbBackupVolumeScript
+	if dbBackup.PersistentVolumeClaim != "" {
+		backupVolumes = append(backupVolumes, corev1.Volume{
+			Name: "db-backup",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dbBackup.PersistentVolumeClaim,
+				},
+			},
+		})
+	} else {
+		// the dump is staged in the pod before the upload
+		script = dbBackupObjectStoreScript
+		backupVolumes = append(backupVolumes, corev1.Volume{
+			Name: "db-backup",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		// a slice keeps the order of the variables, and so the job hash, stable
+		for _, v := range [][2]string{
+			{"S3_ENDPOINT", DBBackupObjectStoreEndpointKey},
+			{"S3_BUCKET", DBBackupObjectStoreBucketKey},
+			{"S3_REGION", DBBackupObjectStoreRegionKey},
+			{"S3_ACCESS_KEY_ID", DBBackupObjectStoreAccessKeyIDKey},
+			{"S3_SECRET_ACCESS_KEY", DBBackupObjectStoreSecretAccessKeyKey},
+		} {
+			secretEnv = append(secretEnv, corev1.EnvVar{
+				Name: v[0],
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: dbBackup.ObjectStoreSecret,
+						},
+						Key: v[1],
+					},
+				},
+			})
+		}
+	}
+
+	// the webhook defaults the image, the objects it did not default fall back
+	// to the same default. The images of the services do not ship mysqldump.
+	image := dbBackup.ContainerImage
+	if image == "" {
+		image = barbicanv1beta1.GetDBClientContainerImage()
+	}
+
+	fsGroup := BarbicanGID
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: instance.RbacResourceName(),
+					SecurityContext: &corev1.PodSecurityContext{
+						FSGroup: &fsGroup,
+					},
+					Volumes: backupVolumes,
+					Containers: []corev1.Container{
+						{
+							Name: name,
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:            []string{"-c", script},
+							Image:           image,
+							SecurityContext: GetBaseSecurityContext(),
+							Env:             env.MergeEnvs(secretEnv, envVars),
+							VolumeMounts:    backupMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+
+	return job
+}
+
+// getDatabaseClientVolumes - the volumes and mounts of the mysql client: the
+// my.cnf of the config-data Secret of the services, and the CA bundle it
+// refers to when the database uses TLS
+func getDatabaseClientVolumes(instance *barbicanv1beta1.Barbican) ([]corev1.Volume, []corev1.VolumeMount) {
+	var config0644AccessMode int32 = 0644
+	volumes := []corev1.Volume{
+		{
+			Name: "db-client-config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &config0644AccessMode,
+					SecretName:  instance.Name + "-config-data",
+					Items: []corev1.KeyToPath{
+						{
+							Key:  "my.cnf",
+							Path: "my.cnf",
+						},
+					},
+				},
+			},
+		},
+	}
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      "db-client-config-data",
+			MountPath: "/etc/my.cnf",
+			SubPath:   "my.cnf",
+			ReadOnly:  true,
+		},
+	}
+
+	if instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, instance.Spec.BarbicanAPI.TLS.CreateVolume())
+		mounts = append(mounts, instance.Spec.BarbicanAPI.TLS.CreateVolumeMounts(nil)...)
+	}
+	return volumes, mounts
+}
+
+// getDatabaseClientEnv - the variables the mysql client connects to the
+// barbican database with, the password is read from the Secret of the account
+func getDatabaseClientEnv(
+	instance *barbicanv1beta1.Barbican,
+	databaseAccount *mariadbv1.MariaDBAccount,
+) ([]corev1.EnvVar, map[string]env.Setter) {
+	secretEnv := []corev1.EnvVar{
+		{
+			Name: "MYSQL_PWD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: databaseAccount.Spec.Secret,
+					},
+					Key: mariadbv1.DatabasePasswordSelector,
+				},
+			},
+		},
+	}
+
+	envVars := map[string]env.Setter{}
+	envVars["DB_HOST"] = env.SetValue(instance.Status.DatabaseHostname)
+	envVars["DB_USER"] = env.SetValue(databaseAccount.Spec.UserName)
+	envVars["DB_NAME"] = env.SetValue(DatabaseName)
+	return secretEnv, envVars
+}
@@ -0,0 +1,102 @@
+package barbican
+
+import (
+	"fmt"
+	"strings"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DBPurgeCommand -
+	DBPurgeCommand = "barbican-manage db clean"
+)
+
+// GetDBPurgeCommand - returns the barbican-manage command cleaning the database
+func GetDBPurgeCommand(dbPurge *barbicanv1beta1.BarbicanDBPurgeTemplate) string {
+	command := []string{DBPurgeCommand, fmt.Sprintf("--min-days %d", dbPurge.Age)}
+	if dbPurge.CleanUnassociatedProjects {
+		command = append(command, "--clean-unassociated-projects")
+	}
+	if dbPurge.SoftDeleteExpiredSecrets {
+		command = append(command, "--soft-delete-expired-secrets")
+	}
+	return strings.Join(command, " ")
+}
+
+// DBPurgeCronJob func
+func DBPurgeCronJob(instance *barbicanv1beta1.Barbican, labels map[string]string, annotations map[string]string) *batchv1.CronJob {
+	// The db purge runs barbican-manage with the same config as the db-sync
+	dbPurgeVolumes, dbPurgeMounts := GetDBSyncVolumes(instance.Name)
+
+	// add CA cert if defined
+	if instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
+		dbPurgeVolumes = append(dbPurgeVolumes, instance.Spec.BarbicanAPI.TLS.CreateVolume())
+		dbPurgeMounts = append(dbPurgeMounts, instance.Spec.BarbicanAPI.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	// add the extraMounts propagated to the db-sync
+	extraVolumes, extraMounts := GetExtraVolumesAndMounts(
+		instance.Spec.ExtraMounts, []storage.PropagationType{Barbican, storage.DBSync})
+	dbPurgeVolumes = append(dbPurgeVolumes, extraVolumes...)
+	dbPurgeMounts = append(dbPurgeMounts, extraMounts...)
+
+	args := []string{"-c", GetDBPurgeCommand(instance.Spec.DBPurge)}
+
+	envVars := map[string]env.Setter{}
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-db-purge",
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          instance.Spec.DBPurge.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: annotations,
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy:      corev1.RestartPolicyOnFailure,
+							ServiceAccountName: instance.RbacResourceName(),
+							Volumes:            dbPurgeVolumes,
+							Containers: []corev1.Container{
+								{
+									Name: instance.Name + "-db-purge",
+									Command: []string{
+										"/bin/bash",
+									},
+									Args:            args,
+									Image:           instance.Spec.BarbicanAPI.ContainerImage,
+									SecurityContext: GetBaseSecurityContext(),
+									Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+									VolumeMounts:    dbPurgeMounts,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if instance.Spec.NodeSelector != nil {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+
+	return cronJob
+}
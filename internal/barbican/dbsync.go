@@ -1,9 +1,12 @@
 package barbican
 
 import (
+	"strings"
+
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
 
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -12,8 +15,34 @@ import (
 const (
 	// DBSyncCommand -
 	DBSyncCommand = "barbican-manage db upgrade"
+
+	// dbSyncScript reports the schema head of the image before upgrading the
+	// database, and the revision the database is at once upgraded, in the
+	// termination message of the container
+	dbSyncScript = `set -e
+head=$(barbican-manage db history 2>/dev/null | awk '/\(head\)/ {print $3; exit}')
+echo "head=${head}" > /dev/termination-log
+` + DBSyncCommand + `
+current=$(barbican-manage db current 2>/dev/null | awk 'NF {rev=$1} END {print rev}')
+echo "head=${head} current=${current}" > /dev/termination-log
+`
 )
 
+// ParseDBSyncMessage - returns the schema head of the image and the revision
+// of the database reported in the termination message of the db-sync, the
+// revision is empty when the upgrade did not complete
+func ParseDBSyncMessage(message string) (string, string) {
+	var head, current string
+	for _, field := range strings.Fields(message) {
+		if value, found := strings.CutPrefix(field, "head="); found {
+			head = value
+		} else if value, found := strings.CutPrefix(field, "current="); found {
+			current = value
+		}
+	}
+	return head, current
+}
+
 // DbSyncJob func
 func DbSyncJob(instance *barbicanv1beta1.Barbican, labels map[string]string, annotations map[string]string) *batchv1.Job {
 	// The dbsync job just needs the main barbican config files
@@ -25,7 +54,13 @@ func DbSyncJob(instance *barbicanv1beta1.Barbican, labels map[string]string, ann
 		dbSyncMounts = append(dbSyncMounts, instance.Spec.BarbicanAPI.TLS.CreateVolumeMounts(nil)...)
 	}
 
-	args := []string{"-c", DBSyncCommand}
+	// add the extraMounts propagated to the db-sync
+	extraVolumes, extraMounts := GetExtraVolumesAndMounts(
+		instance.Spec.ExtraMounts, []storage.PropagationType{Barbican, storage.DBSync})
+	dbSyncVolumes = append(dbSyncVolumes, extraVolumes...)
+	dbSyncMounts = append(dbSyncMounts, extraMounts...)
+
+	args := []string{"-c", dbSyncScript}
 
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
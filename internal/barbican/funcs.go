@@ -1,6 +1,9 @@
 package barbican
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+
 	common "github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/affinity"
 	corev1 "k8s.io/api/core/v1"
@@ -73,3 +76,13 @@ func GetServiceSecurityContext(privileged bool) *corev1.SecurityContext {
 		Privileged:               &privileged,
 	}
 }
+
+// GenerateSimpleCryptoKEK - returns a random base64 encoded key suitable to be
+// used as a Simple Crypto KEK
+func GenerateSimpleCryptoKEK() (string, error) {
+	kek := make([]byte, SimpleCryptoKEKLength)
+	if _, err := rand.Read(kek); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(kek), nil
+}
@@ -0,0 +1,87 @@
+package barbican
+
+import (
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+)
+
+// HSMVendorProfile - describes how the client of an HSM vendor is configured
+type HSMVendorProfile struct {
+	// ConfigFile is the name of the rendered client configuration file
+	ConfigFile string
+	// ConfigPath is where kolla copies the client configuration file
+	ConfigPath string
+	// LibraryPath is the default path of the vendor PKCS11 library
+	LibraryPath string
+	// Env is the environment the vendor library expects
+	Env map[string]string
+}
+
+var hsmVendorProfiles = map[barbicanv1beta1.HSMVendor]HSMVendorProfile{
+	barbicanv1beta1.HSMVendorLuna: {
+		ConfigFile:  "Chrystoki.conf",
+		ConfigPath:  "/etc/Chrystoki.conf",
+		LibraryPath: "/usr/local/luna/libs/64/libCryptoki2.so",
+		Env: map[string]string{
+			"ChrystokiConfigurationPath": "/etc",
+		},
+	},
+	barbicanv1beta1.HSMVendorNShield: {
+		ConfigFile:  "cknfastrc",
+		ConfigPath:  "/opt/nfast/cknfastrc",
+		LibraryPath: "/opt/nfast/toolkits/pkcs11/libcknfast.so",
+		Env: map[string]string{
+			"NFAST_HOME": "/opt/nfast",
+		},
+	},
+	barbicanv1beta1.HSMVendorProteccio: {
+		ConfigFile:  "proteccio.rc",
+		ConfigPath:  "/etc/proteccio/proteccio.rc",
+		LibraryPath: "/usr/lib64/libnethsm.so",
+		Env:         map[string]string{},
+	},
+	barbicanv1beta1.HSMVendorSoftHSM: {
+		ConfigFile:  "softhsm2.conf",
+		ConfigPath:  "/etc/softhsm2.conf",
+		LibraryPath: "/usr/lib64/pkcs11/libsofthsm2.so",
+		Env: map[string]string{
+			"SOFTHSM2_CONF": "/etc/softhsm2.conf",
+		},
+	},
+}
+
+// GetHSMVendorProfile - returns the profile of the HSM vendor, if any
+func GetHSMVendorProfile(vendor barbicanv1beta1.HSMVendor) (HSMVendorProfile, bool) {
+	profile, ok := hsmVendorProfiles[vendor]
+	return profile, ok
+}
+
+// GetHSMVendorTemplates - returns the client config template of the HSM
+// vendor, only the config of the selected vendor is rendered
+func GetHSMVendorTemplates(pkcs11 *barbicanv1beta1.BarbicanPKCS11Template) map[string]string {
+	if pkcs11 == nil {
+		return nil
+	}
+	profile, ok := GetHSMVendorProfile(pkcs11.Vendor)
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		profile.ConfigFile: "/barbican/hsm/" + profile.ConfigFile,
+	}
+}
+
+// GetHSMVendorEnvVars - adds the environment expected by the vendor library to envVars
+func GetHSMVendorEnvVars(pkcs11 *barbicanv1beta1.BarbicanPKCS11Template, envVars map[string]env.Setter) {
+	if pkcs11 == nil {
+		return
+	}
+	profile, ok := GetHSMVendorProfile(pkcs11.Vendor)
+	if !ok {
+		return
+	}
+	for k, v := range profile.Env {
+		envVars[k] = env.SetValue(v)
+	}
+}
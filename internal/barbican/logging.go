@@ -0,0 +1,117 @@
+package barbican
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+)
+
+// defaultModuleLevels are the oslo.log default_log_levels, they keep the
+// libraries quiet when the root logger is at INFO or DEBUG
+var defaultModuleLevels = map[string]barbicanv1beta1.LogLevel{
+	"amqp":           barbicanv1beta1.LogLevelWarning,
+	"amqplib":        barbicanv1beta1.LogLevelWarning,
+	"sqlalchemy":     barbicanv1beta1.LogLevelWarning,
+	"oslo.messaging": barbicanv1beta1.LogLevelInfo,
+	"oslo_messaging": barbicanv1beta1.LogLevelInfo,
+	"iso8601":        barbicanv1beta1.LogLevelWarning,
+	"requests.packages.urllib3.connectionpool": barbicanv1beta1.LogLevelWarning,
+	"urllib3.connectionpool":                   barbicanv1beta1.LogLevelWarning,
+	"websocket":                                barbicanv1beta1.LogLevelWarning,
+	"requests.packages.urllib3.util.retry":     barbicanv1beta1.LogLevelWarning,
+	"urllib3.util.retry":                       barbicanv1beta1.LogLevelWarning,
+	"keystonemiddleware":                       barbicanv1beta1.LogLevelWarning,
+	"routes.middleware":                        barbicanv1beta1.LogLevelWarning,
+	"stevedore":                                barbicanv1beta1.LogLevelWarning,
+	"taskflow":                                 barbicanv1beta1.LogLevelWarning,
+	"keystoneauth":                             barbicanv1beta1.LogLevelWarning,
+	"oslo.cache":                               barbicanv1beta1.LogLevelInfo,
+	"oslo_policy":                              barbicanv1beta1.LogLevelInfo,
+	"dogpile.core.dogpile":                     barbicanv1beta1.LogLevelInfo,
+}
+
+// LogModuleLevel - the level of the logger of a Python module, Key names the
+// logger section in logging.conf
+type LogModuleLevel struct {
+	Key    string
+	Module string
+	Level  barbicanv1beta1.LogLevel
+}
+
+// MergeLogging - returns the logging of a service from the top level logging
+// and the one of the service. The fields set in a later logging override the
+// ones of the previous ones, the module levels are merged.
+func MergeLogging(loggings ...*barbicanv1beta1.BarbicanLoggingTemplate) *barbicanv1beta1.BarbicanLoggingTemplate {
+	var merged *barbicanv1beta1.BarbicanLoggingTemplate
+	for _, logging := range loggings {
+		if logging == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &barbicanv1beta1.BarbicanLoggingTemplate{}
+		}
+		if logging.Level != "" {
+			merged.Level = logging.Level
+		}
+		if logging.JSON != nil {
+			jsonFormat := *logging.JSON
+			merged.JSON = &jsonFormat
+		}
+		if logging.Mode != "" {
+			merged.Mode = logging.Mode
+		}
+		if len(logging.ModuleLevels) != 0 {
+			if merged.ModuleLevels == nil {
+				merged.ModuleLevels = map[string]barbicanv1beta1.LogLevel{}
+			}
+			maps.Copy(merged.ModuleLevels, logging.ModuleLevels)
+		}
+	}
+	return merged
+}
+
+// IsStdoutLogging - returns true if the services log to stdout instead of a
+// file streamed by a log container
+func IsStdoutLogging(logging *barbicanv1beta1.BarbicanLoggingTemplate) bool {
+	return logging != nil && logging.Mode == barbicanv1beta1.LogModeStdout
+}
+
+// GetLoggingTemplateParameters - returns the template parameters rendering
+// the logging options and logging.conf, see MergeLogging for the precedence
+// of the loggings
+func GetLoggingTemplateParameters(loggings ...*barbicanv1beta1.BarbicanLoggingTemplate) map[string]any {
+	logging := MergeLogging(loggings...)
+	if logging == nil {
+		logging = &barbicanv1beta1.BarbicanLoggingTemplate{}
+	}
+	level := barbicanv1beta1.LogLevelInfo
+	if logging.Level != "" {
+		level = logging.Level
+	}
+	jsonFormat := logging.JSON != nil && *logging.JSON
+	moduleLevels := maps.Clone(defaultModuleLevels)
+	maps.Copy(moduleLevels, logging.ModuleLevels)
+
+	modules := []LogModuleLevel{}
+	defaultLogLevels := []string{}
+	for i, module := range slices.Sorted(maps.Keys(moduleLevels)) {
+		modules = append(modules, LogModuleLevel{
+			Key:    fmt.Sprintf("module%d", i),
+			Module: module,
+			Level:  moduleLevels[module],
+		})
+		defaultLogLevels = append(defaultLogLevels, fmt.Sprintf("%s=%s", module, moduleLevels[module]))
+	}
+
+	return map[string]any{
+		"LogLevel":         level,
+		"LogDebug":         level == barbicanv1beta1.LogLevelDebug,
+		"LogJSON":          jsonFormat,
+		"LogStdout":        IsStdoutLogging(logging),
+		"LogModuleLevels":  modules,
+		"LogDefaultLevels": strings.Join(defaultLogLevels, ","),
+	}
+}
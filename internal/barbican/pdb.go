@@ -0,0 +1,34 @@
+package barbican
+
+import (
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pdb"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// PodDisruptionBudget - returns the PodDisruptionBudget of the pods selected
+// by the labels. Without minAvailable and maxUnavailable in the budget, all
+// the replicas but one must stay available, and a single replica can always
+// be evicted so that it does not block node drains.
+func PodDisruptionBudget(
+	name string,
+	namespace string,
+	labels map[string]string,
+	replicas int32,
+	budget *barbicanv1beta1.BarbicanPodDisruptionBudgetTemplate,
+) *policyv1.PodDisruptionBudget {
+	var pdbDef *policyv1.PodDisruptionBudget
+	switch {
+	case budget != nil && budget.MinAvailable != nil:
+		pdbDef = pdb.MinAvailablePodDisruptionBudget(name, namespace, *budget.MinAvailable, labels)
+	case budget != nil && budget.MaxUnavailable != nil:
+		pdbDef = pdb.MaxUnavailablePodDisruptionBudget(name, namespace, *budget.MaxUnavailable, labels)
+	case replicas > 1:
+		pdbDef = pdb.MinAvailablePodDisruptionBudget(name, namespace, intstr.FromInt32(replicas-1), labels)
+	default:
+		pdbDef = pdb.MaxUnavailablePodDisruptionBudget(name, namespace, intstr.FromInt32(1), labels)
+	}
+	pdbDef.Labels = labels
+	return pdbDef
+}
@@ -1,9 +1,13 @@
 package barbican
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
 
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,6 +20,23 @@ const (
 
 // PKCS11PrepJob func
 func PKCS11PrepJob(instance *barbicanv1beta1.Barbican, labels map[string]string, annotations map[string]string) *batchv1.Job {
+	return pkcs11Job(instance, labels, annotations, instance.Name+"-pkcs11-prep", false)
+}
+
+// PKCS11RewrapJob - runs the PKCS11 prep in rotation mode, which generates the
+// MKEK and HMAC keys for the configured labels if needed and rewraps the
+// project KEKs with them
+func PKCS11RewrapJob(instance *barbicanv1beta1.Barbican, labels map[string]string, annotations map[string]string) *batchv1.Job {
+	return pkcs11Job(instance, labels, annotations, instance.Name+"-pkcs11-rewrap", true)
+}
+
+func pkcs11Job(
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	annotations map[string]string,
+	name string,
+	rewrap bool,
+) *batchv1.Job {
 	// The PKCS11 Prep job just needs the main barbican config files, and the files
 	// needed to communicate with the relevant HSM.
 	pkcs11Volumes := []corev1.Volume{
@@ -36,20 +57,36 @@ func PKCS11PrepJob(instance *barbicanv1beta1.Barbican, labels map[string]string,
 	}
 
 	// add any HSM volumes
-	pkcs11Volumes = append(pkcs11Volumes, GetHSMVolumes(*instance.Spec.PKCS11)...)
-	pkcs11Mounts = append(pkcs11Mounts, GetHSMVolumeMounts()...)
+	pkcs11Volumes = append(pkcs11Volumes, GetHSMVolumes(*instance.Spec.PKCS11, instance.Name)...)
+	pkcs11Mounts = append(pkcs11Mounts, GetHSMVolumeMounts(*instance.Spec.PKCS11)...)
 
-	// add luna specific config files
+	// add the extraMounts propagated to all the Barbican pods, e.g. the HSM
+	// vendor library
+	extraVolumes, extraMounts := GetExtraVolumesAndMounts(
+		instance.Spec.ExtraMounts, []storage.PropagationType{Barbican})
+	pkcs11Volumes = append(pkcs11Volumes, extraVolumes...)
+	pkcs11Mounts = append(pkcs11Mounts, extraMounts...)
 
 	args := []string{"-c", PKCS11PrepCommand}
 
 	runAsUser := int64(0)
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	// the job hash only covers the pod spec, the hash of the labels makes
+	// the job run again to generate the keys as soon as the labels change
+	if instance.Spec.PKCS11.MKEKLabel != "" || instance.Spec.PKCS11.HMACLabel != "" {
+		envVars["PKCS11_LABELS_HASH"] = env.SetValue(getPKCS11LabelsHash(instance.Spec.PKCS11))
+	}
+	if rewrap {
+		envVars["PKCS11_REWRAP_PKEK"] = env.SetValue("true")
+	}
+	// add the environment expected by the HSM vendor library, the vendor
+	// client config is copied by kolla from the config-data secret
+	GetHSMVendorEnvVars(instance.Spec.PKCS11, envVars)
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name + "-pkcs11-prep",
+			Name:      name,
 			Namespace: instance.Namespace,
 			Labels:    labels,
 		},
@@ -63,7 +100,7 @@ func PKCS11PrepJob(instance *barbicanv1beta1.Barbican, labels map[string]string,
 					ServiceAccountName: instance.RbacResourceName(),
 					Containers: []corev1.Container{
 						{
-							Name: instance.Name + "-pkcs11-prep",
+							Name: name,
 							Command: []string{
 								"/bin/bash",
 							},
@@ -85,3 +122,9 @@ func PKCS11PrepJob(instance *barbicanv1beta1.Barbican, labels map[string]string,
 
 	return job
 }
+
+// getPKCS11LabelsHash - returns the hash of the MKEK and HMAC labels
+func getPKCS11LabelsHash(pkcs11 *barbicanv1beta1.BarbicanPKCS11Template) string {
+	hash := sha256.Sum256([]byte(pkcs11.MKEKLabel + "\n" + pkcs11.HMACLabel))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,134 @@
+package barbican
+
+import (
+	"context"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/deployment"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// deploymentProgressDeadlineExceeded - the reason of the Progressing
+	// condition of a Deployment whose rollout made no progress in time
+	deploymentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	// defaultProgressDeadlineSeconds - the progress deadline the API server
+	// defaults a Deployment to
+	defaultProgressDeadlineSeconds = 600
+)
+
+// ApplyRolloutStrategy - sets the rolling update of the Deployment from the
+// rollout strategy of the service, the Deployment has to be created or patched
+// with CreateOrPatchDeployment
+func ApplyRolloutStrategy(
+	spec *appsv1.DeploymentSpec,
+	strategy *barbicanv1beta1.BarbicanRolloutStrategyTemplate,
+) {
+	if strategy == nil {
+		return
+	}
+	if strategy.MaxSurge != nil || strategy.MaxUnavailable != nil {
+		spec.Strategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{
+				MaxSurge:       strategy.MaxSurge,
+				MaxUnavailable: strategy.MaxUnavailable,
+			},
+		}
+	}
+	if strategy.MinReadySeconds != nil {
+		spec.MinReadySeconds = *strategy.MinReadySeconds
+	}
+	spec.ProgressDeadlineSeconds = strategy.ProgressDeadlineSeconds
+	spec.Paused = strategy.Paused
+}
+
+// CreateOrPatchDeployment - creates or patches the Deployment of the service.
+// The lib-common Deployment only sets the selector, the metadata, the pod
+// template, the replicas and the strategy, the other rollout fields of the
+// definition are patched here. They are patched first, so that a pause
+// applies to the pod template patched in the same reconciliation, and once
+// more for a Deployment which did not exist yet.
+func CreateOrPatchDeployment(
+	ctx context.Context,
+	h *helper.Helper,
+	depl *deployment.Deployment,
+	deplDef *appsv1.Deployment,
+) (ctrl.Result, error) {
+	err := patchRolloutFields(ctx, h, deplDef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ctrlResult, err := depl.CreateOrPatch(ctx, h)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
+	return ctrl.Result{}, patchRolloutFields(ctx, h, deplDef)
+}
+
+// patchRolloutFields - sets the MinReadySeconds, ProgressDeadlineSeconds and
+// Paused of the existing Deployment to the ones of the definition
+func patchRolloutFields(
+	ctx context.Context,
+	h *helper.Helper,
+	deplDef *appsv1.Deployment,
+) error {
+	deploy := &appsv1.Deployment{}
+	err := h.GetClient().Get(ctx, types.NamespacedName{Name: deplDef.Name, Namespace: deplDef.Namespace}, deploy)
+	if k8s_errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	progressDeadlineSeconds := deplDef.Spec.ProgressDeadlineSeconds
+	if progressDeadlineSeconds == nil {
+		progressDeadlineSeconds = ptr.To[int32](defaultProgressDeadlineSeconds)
+	}
+	if deploy.Spec.MinReadySeconds == deplDef.Spec.MinReadySeconds &&
+		ptr.Equal(deploy.Spec.ProgressDeadlineSeconds, progressDeadlineSeconds) &&
+		deploy.Spec.Paused == deplDef.Spec.Paused {
+		return nil
+	}
+
+	patch := client.MergeFrom(deploy.DeepCopy())
+	deploy.Spec.MinReadySeconds = deplDef.Spec.MinReadySeconds
+	deploy.Spec.ProgressDeadlineSeconds = progressDeadlineSeconds
+	deploy.Spec.Paused = deplDef.Spec.Paused
+	return h.GetClient().Patch(ctx, deploy, patch)
+}
+
+// IsRolledOut - returns true when the Deployment is ready and all its
+// replicas run the current pod template, the pods of the previous
+// ReplicaSets being ready is not enough
+func IsRolledOut(deploy appsv1.Deployment) bool {
+	return deployment.IsReady(deploy) &&
+		deploy.Status.UpdatedReplicas == deploy.Status.Replicas
+}
+
+// GetStalledRollout - returns the reason and the message of the rollout of the
+// Deployment when it is stalled: the ReplicaSet of the rollout fails to create
+// its pods, or the rollout made no progress before its deadline
+func GetStalledRollout(deploy appsv1.Deployment) (string, string, bool) {
+	// the failure of the ReplicaSet is the most specific, e.g. a quota
+	// forbidding the new pods
+	for _, c := range deploy.Status.Conditions {
+		if c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue {
+			return c.Reason, c.Message, true
+		}
+	}
+	for _, c := range deploy.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse &&
+			c.Reason == deploymentProgressDeadlineExceeded {
+			return c.Reason, c.Message, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,251 @@
+package barbican
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+)
+
+var (
+	// ErrSecretStoreAPI - the Keystone or Barbican API returned an unexpected response
+	ErrSecretStoreAPI = errors.New("unexpected secret store API response")
+	// ErrUnknownSecretStore - a project prefers a secret store matching none of the spec
+	ErrUnknownSecretStore = errors.New("unknown secret store")
+	// ErrInvalidCABundle - the CA bundle holds no PEM certificate
+	ErrInvalidCABundle = errors.New("no PEM certificate in the CA bundle")
+)
+
+// SecretStoreRequestTimeout - the timeout of each request to the Keystone and
+// Barbican APIs, kept short as the requests run in the reconciliation
+const SecretStoreRequestTimeout = time.Duration(5) * time.Second
+
+// secretStorePlugins maps the secret stores to the secret store and crypto
+// plugins the Barbican API reports for them
+var secretStorePlugins = map[barbicanv1beta1.SecretStore]struct {
+	storePlugin  string
+	cryptoPlugin string
+}{
+	barbicanv1beta1.SecretStoreSimpleCrypto: {"store_crypto", "simple_crypto"},
+	barbicanv1beta1.SecretStorePKCS11:       {"store_crypto", "p11_crypto"},
+	barbicanv1beta1.SecretStoreKMIP:         {"kmip_plugin", ""},
+	barbicanv1beta1.SecretStoreVault:        {"vault_plugin", ""},
+}
+
+// SecretStoreInfo - a secret store as reported by the Barbican API
+type SecretStoreInfo struct {
+	Name              string `json:"name"`
+	SecretStoreRef    string `json:"secret_store_ref"`
+	SecretStorePlugin string `json:"secret_store_plugin"`
+	CryptoPlugin      string `json:"crypto_plugin"`
+	GlobalDefault     bool   `json:"global_default"`
+}
+
+// SecretStore - returns the secret store of the spec matching the plugins of
+// the Barbican secret store, if any
+func (s *SecretStoreInfo) SecretStore() (barbicanv1beta1.SecretStore, bool) {
+	for store, plugins := range secretStorePlugins {
+		if plugins.storePlugin == s.SecretStorePlugin &&
+			(plugins.cryptoPlugin == "" || plugins.cryptoPlugin == s.CryptoPlugin) {
+			return store, true
+		}
+	}
+	return "", false
+}
+
+// SecretStoreClient - manages the preferred secret store of projects through
+// the Barbican API, with tokens of the service user scoped to these projects
+type SecretStoreClient struct {
+	KeystoneURL string
+	BarbicanURL string
+	Username    string
+	Password    string
+	UserDomain  string
+	HTTPClient  *http.Client
+}
+
+// NewSecretStoreClient - returns a SecretStoreClient, transport may be nil to
+// use the default transport
+func NewSecretStoreClient(
+	keystoneURL string,
+	barbicanURL string,
+	username string,
+	password string,
+	transport http.RoundTripper,
+) *SecretStoreClient {
+	return &SecretStoreClient{
+		KeystoneURL: keystoneURL,
+		BarbicanURL: barbicanURL,
+		Username:    username,
+		Password:    password,
+		UserDomain:  "Default",
+		HTTPClient: &http.Client{
+			Transport: transport,
+			Timeout:   SecretStoreRequestTimeout,
+		},
+	}
+}
+
+// NewSecretStoreTransport - returns a transport trusting the system CAs and the
+// CAs of the PEM bundle, the default transport when the bundle is empty
+func NewSecretStoreTransport(caBundle []byte) (http.RoundTripper, error) {
+	if len(caBundle) == 0 {
+		return nil, nil
+	}
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, ErrInvalidCABundle
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	return transport, nil
+}
+
+// ProjectToken - returns a token of the service user scoped to the project
+func (c *SecretStoreClient) ProjectToken(ctx context.Context, projectID string) (string, error) {
+	body := map[string]any{
+		"auth": map[string]any{
+			"identity": map[string]any{
+				"methods": []string{"password"},
+				"password": map[string]any{
+					"user": map[string]any{
+						"name":     c.Username,
+						"domain":   map[string]string{"name": c.UserDomain},
+						"password": c.Password,
+					},
+				},
+			},
+			"scope": map[string]any{
+				"project": map[string]string{"id": projectID},
+			},
+		},
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	authURL := strings.TrimSuffix(c.KeystoneURL, "/")
+	if !strings.HasSuffix(authURL, "/v3") {
+		authURL += "/v3"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, authURL+"/auth/tokens", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("%w: keystone returned %s for project %s", ErrSecretStoreAPI, resp.Status, projectID)
+	}
+	token := resp.Header.Get("X-Subject-Token")
+	if token == "" {
+		return "", fmt.Errorf("%w: keystone returned no token for project %s", ErrSecretStoreAPI, projectID)
+	}
+	return token, nil
+}
+
+// ListSecretStores - returns the secret stores registered in Barbican
+func (c *SecretStoreClient) ListSecretStores(ctx context.Context, token string) ([]SecretStoreInfo, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v1/secret-stores", token)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: listing secret stores returned %s", ErrSecretStoreAPI, resp.Status)
+	}
+
+	stores := struct {
+		SecretStores []SecretStoreInfo `json:"secret_stores"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&stores); err != nil {
+		return nil, err
+	}
+	return stores.SecretStores, nil
+}
+
+// GetPreferredSecretStore - returns the preferred secret store of the project
+// of the token, nil when the project uses the global default
+func (c *SecretStoreClient) GetPreferredSecretStore(ctx context.Context, token string) (*SecretStoreInfo, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v1/secret-stores/preferred", token)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: getting the preferred secret store returned %s", ErrSecretStoreAPI, resp.Status)
+	}
+
+	store := &SecretStoreInfo{}
+	if err := json.NewDecoder(resp.Body).Decode(store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// SetPreferredSecretStore - sets the preferred secret store of the project of the token
+func (c *SecretStoreClient) SetPreferredSecretStore(ctx context.Context, token string, store *SecretStoreInfo) error {
+	return c.preferred(ctx, http.MethodPost, token, store)
+}
+
+// UnsetPreferredSecretStore - removes the preferred secret store of the project of the token
+func (c *SecretStoreClient) UnsetPreferredSecretStore(ctx context.Context, token string, store *SecretStoreInfo) error {
+	return c.preferred(ctx, http.MethodDelete, token, store)
+}
+
+func (c *SecretStoreClient) preferred(ctx context.Context, method string, token string, store *SecretStoreInfo) error {
+	resp, err := c.do(ctx, method, "/v1/secret-stores/"+path.Base(store.SecretStoreRef)+"/preferred", token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s of the preferred secret store %s returned %s",
+			ErrSecretStoreAPI, method, store.Name, resp.Status)
+	}
+	return nil
+}
+
+func (c *SecretStoreClient) do(ctx context.Context, method string, uri string, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BarbicanURL, "/")+uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Auth-Token", token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// drain the body so that the connection can be reused
+	if resp.StatusCode >= http.StatusBadRequest {
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}
+	return resp, nil
+}
@@ -0,0 +1,139 @@
+package barbican
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SecretStoreMigrationCommand -
+	SecretStoreMigrationCommand = "/usr/local/bin/kolla_start"
+)
+
+// GetSecretStoreMigrationJobName - returns the name of the secret store
+// migration Job of a Barbican
+func GetSecretStoreMigrationJobName(name string) string {
+	return name + "-secret-store-migration"
+}
+
+// ParseSecretStoreMigrationMessage - returns the number of secrets found in
+// the source store, migrated and failed to migrate, reported in the
+// termination message of the migration
+func ParseSecretStoreMigrationMessage(message string) (int32, int32, int32) {
+	var total, migrated, failed int32
+	for _, field := range strings.Fields(message) {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "total":
+			total = int32(count)
+		case "migrated":
+			migrated = int32(count)
+		case "failed":
+			failed = int32(count)
+		}
+	}
+	return total, migrated, failed
+}
+
+// SecretStoreMigrationJob - re-encrypts the secrets of the source secret store
+// with the target one
+func SecretStoreMigrationJob(
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	annotations map[string]string,
+	migration *barbicanv1beta1.SecretStoreMigrationStatus,
+) *batchv1.Job {
+	// The migration job needs the main barbican config files, and the files
+	// needed to communicate with the HSM when pkcs11 is involved.
+	migrationVolumes := []corev1.Volume{
+		GetScriptVolume(instance.Name + "-scripts"),
+	}
+	migrationVolumes = append(migrationVolumes, GetVolumes(instance.Name)...)
+
+	migrationMounts := []corev1.VolumeMount{
+		GetKollaConfigVolumeMount(instance.Name + "-secret-store-migration"),
+		GetScriptVolumeMount(),
+	}
+	migrationMounts = append(migrationMounts, GetVolumeMounts()...)
+
+	// add CA cert if defined
+	if instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
+		migrationVolumes = append(migrationVolumes, instance.Spec.BarbicanAPI.TLS.CreateVolume())
+		migrationMounts = append(migrationMounts, instance.Spec.BarbicanAPI.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	args := []string{"-c", SecretStoreMigrationCommand}
+
+	envVars := map[string]env.Setter{}
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	// the stores and the time the migration was requested are part of the
+	// job hash, so every migration, and every retry, runs a new job
+	envVars["SECRET_STORE_MIGRATION_SOURCE"] = env.SetValue(string(migration.Source))
+	envVars["SECRET_STORE_MIGRATION_TARGET"] = env.SetValue(string(migration.Target))
+	if migration.StartTime != nil {
+		envVars["SECRET_STORE_MIGRATION_START_TIME"] = env.SetValue(migration.StartTime.UTC().Format(time.RFC3339))
+	}
+
+	// add any HSM volumes
+	if slices.Contains([]barbicanv1beta1.SecretStore{migration.Source, migration.Target}, barbicanv1beta1.SecretStorePKCS11) &&
+		instance.Spec.PKCS11 != nil {
+		migrationVolumes = append(migrationVolumes, GetHSMVolumes(*instance.Spec.PKCS11, instance.Name)...)
+		migrationMounts = append(migrationMounts, GetHSMVolumeMounts(*instance.Spec.PKCS11)...)
+		GetHSMVendorEnvVars(instance.Spec.PKCS11, envVars)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSecretStoreMigrationJobName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: instance.RbacResourceName(),
+					Volumes:            migrationVolumes,
+					Containers: []corev1.Container{
+						{
+							Name: instance.Name + "-secret-store-migration",
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:            args,
+							Image:           instance.Spec.BarbicanAPI.ContainerImage,
+							SecurityContext: GetBaseSecurityContext(),
+							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:    migrationMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+
+	return job
+}
@@ -0,0 +1,79 @@
+package barbican
+
+import (
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SimpleCryptoKEKRewrapCommand -
+	SimpleCryptoKEKRewrapCommand = "barbican-manage simple_crypto rewrap_pkek"
+)
+
+// SimpleCryptoKEKRewrapJob func
+func SimpleCryptoKEKRewrapJob(
+	instance *barbicanv1beta1.Barbican,
+	labels map[string]string,
+	annotations map[string]string,
+	activeKEK string,
+) *batchv1.Job {
+	// The rewrap job just needs the main barbican config files, which render
+	// the new active KEK first followed by the KEK being rotated out.
+	rewrapVolumes, rewrapMounts := GetDBSyncVolumes(instance.Name)
+
+	// add CA cert if defined
+	if instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
+		rewrapVolumes = append(rewrapVolumes, instance.Spec.BarbicanAPI.TLS.CreateVolume())
+		rewrapMounts = append(rewrapMounts, instance.Spec.BarbicanAPI.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	args := []string{"-c", SimpleCryptoKEKRewrapCommand}
+
+	envVars := map[string]env.Setter{}
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	// the active KEK is part of the job hash, so every rotation runs a new job
+	envVars["SIMPLE_CRYPTO_ACTIVE_KEK"] = env.SetValue(activeKEK)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-simple-crypto-kek-rewrap",
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: instance.RbacResourceName(),
+					Volumes:            rewrapVolumes,
+					Containers: []corev1.Container{
+						{
+							Name: instance.Name + "-simple-crypto-kek-rewrap",
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:            args,
+							Image:           instance.Spec.BarbicanAPI.ContainerImage,
+							SecurityContext: GetBaseSecurityContext(),
+							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:    rewrapMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+
+	return job
+}
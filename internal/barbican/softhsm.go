@@ -0,0 +1,208 @@
+package barbican
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SoftHSMInitCommand -
+	SoftHSMInitCommand = "/usr/local/bin/container-scripts/init_softhsm_token.sh"
+)
+
+// IsSoftHSM - returns true if the PKCS11 plugin uses a SoftHSM token
+func IsSoftHSM(pkcs11 *barbicanv1beta1.BarbicanPKCS11Template) bool {
+	return pkcs11 != nil && pkcs11.Vendor == barbicanv1beta1.HSMVendorSoftHSM
+}
+
+// SoftHSMTokenPVCName - returns the name of the PVC holding the SoftHSM tokens
+func SoftHSMTokenPVCName(barbicanName string) string {
+	return barbicanName + "-softhsm-tokens"
+}
+
+// GenerateSoftHSMPin - returns a random hex encoded PIN for the SoftHSM token
+func GenerateSoftHSMPin() (string, error) {
+	pin := make([]byte, SoftHSMPinLength)
+	if _, err := rand.Read(pin); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(pin), nil
+}
+
+// GetSoftHSMVolumes returns the Volumes for the SoftHSM token store
+func GetSoftHSMVolumes(barbicanName string) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: SoftHSMTokenVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: SoftHSMTokenPVCName(barbicanName),
+				},
+			},
+		},
+	}
+}
+
+// GetSoftHSMVolumeMounts returns the Volume Mounts for the SoftHSM token store
+func GetSoftHSMVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      SoftHSMTokenVolume,
+			MountPath: SoftHSMTokenMountPoint,
+		},
+	}
+}
+
+// SoftHSMTokenPVC - returns the PVC holding the SoftHSM tokens
+func SoftHSMTokenPVC(instance *barbicanv1beta1.Barbican, labels map[string]string) (*corev1.PersistentVolumeClaim, error) {
+	softHSM := instance.Spec.PKCS11.SoftHSM
+	if softHSM == nil {
+		softHSM = &barbicanv1beta1.BarbicanSoftHSMTemplate{}
+	}
+	storageRequest := softHSM.StorageRequest
+	if storageRequest == "" {
+		storageRequest = barbicanv1beta1.DefaultSoftHSMStorageRequest
+	}
+	quantity, err := resource.ParseQuantity(storageRequest)
+	if err != nil {
+		return nil, err
+	}
+	// the API and worker pods mounting the token can run on different nodes
+	accessMode := softHSM.AccessMode
+	if accessMode == "" {
+		accessMode = corev1.ReadWriteMany
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SoftHSMTokenPVCName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				accessMode,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: quantity,
+				},
+			},
+		},
+	}
+	if softHSM.StorageClass != "" {
+		pvc.Spec.StorageClassName = &softHSM.StorageClass
+	}
+
+	return pvc, nil
+}
+
+// SoftHSMInitJob - initialises the SoftHSM token with the PINs from the LoginSecret
+func SoftHSMInitJob(instance *barbicanv1beta1.Barbican, labels map[string]string, annotations map[string]string) *batchv1.Job {
+	name := instance.Name + "-softhsm-init"
+
+	initVolumes := []corev1.Volume{
+		GetScriptVolume(instance.Name + "-scripts"),
+	}
+	initVolumes = append(initVolumes, GetVolumes(instance.Name)...)
+	initVolumes = append(initVolumes, GetSoftHSMVolumes(instance.Name)...)
+
+	initMounts := []corev1.VolumeMount{
+		GetScriptVolumeMount(),
+	}
+	initMounts = append(initMounts, GetVolumeMounts()...)
+	initMounts = append(initMounts, GetSoftHSMVolumeMounts()...)
+
+	envVars := map[string]env.Setter{}
+	// the job does not go through kolla, so the SoftHSM config is read from
+	// the config-data secret directly
+	envVars["SOFTHSM2_CONF"] = env.SetValue(ConfigMountPoint + "/softhsm2.conf")
+	// the token label is part of the job hash so that a new token gets
+	// initialised as soon as the label changes
+	envVars["SOFTHSM_TOKEN_LABEL"] = env.SetValue(GetSoftHSMTokenLabel(instance.Spec.PKCS11))
+
+	pinEnv := []corev1.EnvVar{
+		{
+			Name: "SOFTHSM_PIN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: instance.Spec.PKCS11.LoginSecret,
+					},
+					Key: instance.Spec.PasswordSelectors.PKCS11Pin,
+				},
+			},
+		},
+		{
+			Name: "SOFTHSM_SO_PIN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: instance.Spec.PKCS11.LoginSecret,
+					},
+					Key: SoftHSMSOPinKey,
+				},
+			},
+		},
+	}
+
+	runAsUser := BarbicanUID
+	fsGroup := BarbicanGID
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: instance.RbacResourceName(),
+					SecurityContext: &corev1.PodSecurityContext{
+						FSGroup: &fsGroup,
+					},
+					Containers: []corev1.Container{
+						{
+							Name: name,
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:  []string{"-c", SoftHSMInitCommand},
+							Image: instance.Spec.BarbicanAPI.ContainerImage,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsUser: &runAsUser,
+							},
+							Env:          env.MergeEnvs(pinEnv, envVars),
+							VolumeMounts: initMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	job.Spec.Template.Spec.Volumes = initVolumes
+
+	return job
+}
+
+// GetSoftHSMTokenLabel - returns the label of the SoftHSM token
+func GetSoftHSMTokenLabel(pkcs11 *barbicanv1beta1.BarbicanPKCS11Template) string {
+	if pkcs11.TokenLabel != "" {
+		return pkcs11.TokenLabel
+	}
+	return SoftHSMDefaultTokenLabel
+}
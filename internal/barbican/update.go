@@ -0,0 +1,38 @@
+package barbican
+
+import (
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+)
+
+// GetTargetImages - returns the container images of the services in the spec
+func GetTargetImages(instance *barbicanv1beta1.Barbican) barbicanv1beta1.BarbicanUpdateImages {
+	return barbicanv1beta1.BarbicanUpdateImages{
+		API:              instance.Spec.BarbicanAPI.ContainerImage,
+		Worker:           instance.Spec.BarbicanWorker.ContainerImage,
+		KeystoneListener: instance.Spec.BarbicanKeystoneListener.ContainerImage,
+	}
+}
+
+// GetContainerImages - returns the container images the services run in the
+// current phase of the minor update: the services keep their previous images
+// during the db-sync, then the keystone listener and the worker move to the
+// target images before the API does
+func GetContainerImages(instance *barbicanv1beta1.Barbican) barbicanv1beta1.BarbicanUpdateImages {
+	update := instance.Status.Update
+	if update == nil || update.TargetImages != GetTargetImages(instance) {
+		return GetTargetImages(instance)
+	}
+
+	switch update.Phase {
+	case barbicanv1beta1.BarbicanUpdateDBSync:
+		return update.PreviousImages
+	case barbicanv1beta1.BarbicanUpdateKeystoneListenerAndWorker:
+		return barbicanv1beta1.BarbicanUpdateImages{
+			API:              update.PreviousImages.API,
+			Worker:           update.TargetImages.Worker,
+			KeystoneListener: update.TargetImages.KeystoneListener,
+		}
+	default:
+		return update.TargetImages
+	}
+}
@@ -1,8 +1,12 @@
 package barbican
 
 import (
+	"slices"
+
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 )
 
 var (
@@ -95,9 +99,17 @@ func GetKollaConfigVolumeMount(serviceName string) corev1.VolumeMount {
 	}
 }
 
-// GetHSMVolumes returns Volumes for HSM secrets
-func GetHSMVolumes(pkcs11 barbicanv1beta1.BarbicanPKCS11Template) []corev1.Volume {
-	return []corev1.Volume{
+// GetHSMVolumes returns Volumes for HSM secrets, and the SoftHSM token store
+// of the Barbican named barbicanName when the softhsm vendor is used
+func GetHSMVolumes(pkcs11 barbicanv1beta1.BarbicanPKCS11Template, barbicanName string) []corev1.Volume {
+	volumes := []corev1.Volume{}
+	if IsSoftHSM(&pkcs11) {
+		volumes = append(volumes, GetSoftHSMVolumes(barbicanName)...)
+	}
+	if pkcs11.ClientDataSecret == "" {
+		return volumes
+	}
+	return append(volumes, []corev1.Volume{
 		{
 			Name: PKCS11ClientDataVolume,
 			VolumeSource: corev1.VolumeSource{
@@ -107,20 +119,123 @@ func GetHSMVolumes(pkcs11 barbicanv1beta1.BarbicanPKCS11Template) []corev1.Volum
 				},
 			},
 		},
-	}
+	}...)
 }
 
 // GetHSMVolumeMounts returns Volume Mounts for HSM secrets
-func GetHSMVolumeMounts() []corev1.VolumeMount {
+func GetHSMVolumeMounts(pkcs11 barbicanv1beta1.BarbicanPKCS11Template) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{}
+	if IsSoftHSM(&pkcs11) {
+		volumeMounts = append(volumeMounts, GetSoftHSMVolumeMounts()...)
+	}
+	if pkcs11.ClientDataSecret == "" {
+		return volumeMounts
+	}
+	return append(volumeMounts, corev1.VolumeMount{
+		Name:      PKCS11ClientDataVolume,
+		MountPath: PKCS11ClientDataMountPoint,
+		ReadOnly:  true,
+	})
+}
+
+// GetKMIPVolumes returns Volumes for the KMIP client certificate and CA secrets
+func GetKMIPVolumes(kmip barbicanv1beta1.BarbicanKMIPTemplate) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: KMIPClientDataVolume,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					DefaultMode: &configMode,
+					Sources: []corev1.VolumeProjection{
+						{
+							Secret: &corev1.SecretProjection{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: kmip.ClientCertSecret,
+								},
+								Items: []corev1.KeyToPath{
+									{
+										Key:  KMIPClientCertKey,
+										Path: "client.crt",
+									},
+									{
+										Key:  KMIPClientKeyKey,
+										Path: "client.key",
+									},
+								},
+							},
+						},
+						{
+							Secret: &corev1.SecretProjection{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: kmip.CASecret,
+								},
+								Items: []corev1.KeyToPath{
+									{
+										Key:  KMIPCAKey,
+										Path: "ca.crt",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// GetKMIPVolumeMounts returns Volume Mounts for the KMIP client certificates
+func GetKMIPVolumeMounts() []corev1.VolumeMount {
 	return []corev1.VolumeMount{
 		{
-			Name:      PKCS11ClientDataVolume,
-			MountPath: PKCS11ClientDataMountPoint,
+			Name:      KMIPClientDataVolume,
+			MountPath: KMIPClientDataMountPoint,
 			ReadOnly:  true,
 		},
 	}
 }
 
+// GetVaultVolumes returns Volumes for the Vault CA secret
+func GetVaultVolumes(vault barbicanv1beta1.BarbicanVaultTemplate) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: VaultCAVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  vault.CASecret,
+					DefaultMode: &configMode,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  VaultCAKey,
+							Path: "ca.crt",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// GetVaultVolumeMounts returns Volume Mounts for the Vault CA certificate
+func GetVaultVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      VaultCAVolume,
+			MountPath: VaultCAMountPoint,
+			ReadOnly:  true,
+		},
+	}
+}
+
+// GetVaultAuthSecretKeys returns the keys expected in the Vault AuthSecret
+// for the given authentication method
+func GetVaultAuthSecretKeys(method barbicanv1beta1.VaultAuthMethod) []string {
+	if method == barbicanv1beta1.VaultAuthMethodAppRole {
+		return []string{VaultRoleIDKey, VaultSecretIDKey}
+	}
+	return []string{VaultTokenKey}
+}
+
 // GetCustomConfigVolume - service custom config volume
 func GetCustomConfigVolume(name string) corev1.Volume {
 	var config0644AccessMode int32 = 0644
@@ -184,3 +299,40 @@ func GetDBSyncVolumes(name string) ([]corev1.Volume, []corev1.VolumeMount) {
 	}
 	return dbSyncVolumes, dbSyncVolumeMount
 }
+
+// GetExtraVolumesAndMounts - the extraMounts volumes and mounts propagated to
+// the given services. A volume or mount listed several times, or propagated
+// through several of the services, is only added once. The webhook rejects
+// different volumes with the same name or mount path, they are all kept here
+// so that the API server refuses the pod instead of one of them being dropped.
+func GetExtraVolumesAndMounts(
+	extraMounts []storage.VolMounts,
+	svc []storage.PropagationType,
+) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := []corev1.Volume{}
+	mounts := []corev1.VolumeMount{}
+	for _, extraMount := range extraMounts {
+		for _, vm := range extraMount.Propagate(svc) {
+			for _, v := range vm.Volumes {
+				// the conversion of the slimmed down volume source
+				// to the core one cannot fail
+				volumeSource, _ := v.ToCoreVolumeSource()
+				volume := corev1.Volume{
+					Name:         v.Name,
+					VolumeSource: *volumeSource,
+				}
+				if slices.ContainsFunc(volumes, func(vol corev1.Volume) bool { return equality.Semantic.DeepEqual(vol, volume) }) {
+					continue
+				}
+				volumes = append(volumes, volume)
+			}
+			for _, m := range vm.Mounts {
+				if slices.ContainsFunc(mounts, func(mount corev1.VolumeMount) bool { return equality.Semantic.DeepEqual(mount, m) }) {
+					continue
+				}
+				mounts = append(mounts, m)
+			}
+		}
+	}
+	return volumes, mounts
+}
@@ -0,0 +1,92 @@
+package barbican
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultWSGIProcesses - the daemon processes of an API without explicit
+	// tuning, a CPU request only raises them
+	DefaultWSGIProcesses = 8
+	// DefaultWSGIThreads - the threads of each daemon process
+	DefaultWSGIThreads = 1
+	// WSGIProcessesPerCPU - the daemon processes per CPU requested by the API
+	WSGIProcessesPerCPU = 2
+)
+
+// ErrWSGITuningNotFound - the httpd config holds no WSGIDaemonProcess directive
+var ErrWSGITuningNotFound = errors.New("no WSGIDaemonProcess directive in the httpd config")
+
+// GetWSGITuning - returns the mod_wsgi tuning of the API. The settings of the
+// spec win, the processes are otherwise derived from the CPU request and never
+// fall below the default.
+func GetWSGITuning(
+	wsgi barbicanv1beta1.BarbicanAPIWSGI,
+	resources corev1.ResourceRequirements,
+) barbicanv1beta1.BarbicanAPIWSGIStatus {
+	tuning := barbicanv1beta1.BarbicanAPIWSGIStatus{
+		Processes: DefaultWSGIProcesses,
+		Threads:   DefaultWSGIThreads,
+	}
+
+	if cpu, ok := resources.Requests[corev1.ResourceCPU]; ok && !cpu.IsZero() {
+		processes := (cpu.MilliValue()*WSGIProcessesPerCPU + 999) / 1000
+		if processes > DefaultWSGIProcesses {
+			tuning.Processes = int32(processes) // #nosec G115
+		}
+	}
+
+	if wsgi.Processes != nil {
+		tuning.Processes = *wsgi.Processes
+	}
+	if wsgi.Threads != nil {
+		tuning.Threads = *wsgi.Threads
+	}
+	if wsgi.MaximumRequests != nil {
+		tuning.MaximumRequests = *wsgi.MaximumRequests
+	}
+	if wsgi.GracefulTimeout != nil {
+		tuning.GracefulTimeout = *wsgi.GracefulTimeout
+	}
+	return tuning
+}
+
+// ParseWSGITuning - returns the mod_wsgi tuning of the first WSGIDaemonProcess
+// directive of the rendered httpd config
+func ParseWSGITuning(httpdConf []byte) (*barbicanv1beta1.BarbicanAPIWSGIStatus, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(httpdConf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "WSGIDaemonProcess" {
+			continue
+		}
+		tuning := &barbicanv1beta1.BarbicanAPIWSGIStatus{}
+		options := map[string]*int32{
+			"processes":        &tuning.Processes,
+			"threads":          &tuning.Threads,
+			"maximum-requests": &tuning.MaximumRequests,
+			"graceful-timeout": &tuning.GracefulTimeout,
+		}
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, "=")
+			option, ok := options[key]
+			if !found || !ok {
+				continue
+			}
+			parsed, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			*option = int32(parsed)
+		}
+		return tuning, nil
+	}
+	return nil, ErrWSGITuningNotFound
+}
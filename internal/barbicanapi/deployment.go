@@ -2,6 +2,8 @@
 package barbicanapi
 
 import (
+	"slices"
+
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	appsv1 "k8s.io/api/apps/v1"
@@ -30,33 +32,23 @@ func Deployment(
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
-	livenessProbe := &corev1.Probe{
-		// TODO might need tuning
-		TimeoutSeconds:      5,
-		PeriodSeconds:       3,
-		InitialDelaySeconds: 5,
-	}
-	readinessProbe := &corev1.Probe{
-		// TODO might need tuning
-		TimeoutSeconds:      5,
-		PeriodSeconds:       5,
-		InitialDelaySeconds: 5,
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) {
+		barbican.GetHSMVendorEnvVars(instance.Spec.PKCS11, envVars)
 	}
 	args := []string{"-c", ServiceCommand}
 	//
 	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
 	//
-	livenessProbe.HTTPGet = &corev1.HTTPGetAction{
-		Path: "/healthcheck",
-		Port: intstr.IntOrString{Type: intstr.Int, IntVal: int32(barbican.BarbicanPublicPort)},
+	healthcheck := corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: "/healthcheck",
+			Port: intstr.IntOrString{Type: intstr.Int, IntVal: int32(barbican.BarbicanPublicPort)},
+		},
 	}
-	readinessProbe.HTTPGet = livenessProbe.HTTPGet
-
 	if instance.Spec.TLS.API.Enabled(service.EndpointPublic) {
-		livenessProbe.HTTPGet.Scheme = corev1.URISchemeHTTPS
-		readinessProbe.HTTPGet.Scheme = corev1.URISchemeHTTPS
+		healthcheck.HTTPGet.Scheme = corev1.URISchemeHTTPS
 	}
-	readinessProbe.HTTPGet = livenessProbe.HTTPGet
+	livenessProbe, readinessProbe, startupProbe := barbican.GetProbes(healthcheck, healthcheck, instance.Spec.Probes)
 
 	apiVolumes, apiVolumeMounts, err := GetAPIVolumesAndMounts(instance)
 	if err != nil {
@@ -101,8 +93,8 @@ func Deployment(
 							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
 							VolumeMounts:    []corev1.VolumeMount{barbican.GetLogVolumeMount()},
 							Resources:       instance.Spec.Resources,
-							ReadinessProbe:  readinessProbe,
-							LivenessProbe:   livenessProbe,
+							// the log container only streams the log file, the
+							// probes of the API would restart it with the API
 						},
 						{
 							Name: barbican.ServiceName + "-api",
@@ -117,6 +109,7 @@ func Deployment(
 							Resources:       instance.Spec.Resources,
 							ReadinessProbe:  readinessProbe,
 							LivenessProbe:   livenessProbe,
+							StartupProbe:    startupProbe,
 						},
 					},
 				},
@@ -124,10 +117,20 @@ func Deployment(
 		},
 	}
 
+	// the service logs to stdout, there is no log file to stream
+	if barbican.IsStdoutLogging(instance.Spec.Logging) {
+		deployment.Spec.Template.Spec.Containers = slices.DeleteFunc(
+			deployment.Spec.Template.Spec.Containers,
+			func(c corev1.Container) bool { return c.Name == instance.Name+"-log" },
+		)
+	}
+
 	if instance.Spec.NodeSelector != nil {
 		deployment.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
 	}
 
+	barbican.ApplyRolloutStrategy(&deployment.Spec, instance.Spec.RolloutStrategy)
+
 	if topology != nil {
 		topology.ApplyTo(&deployment.Spec.Template)
 	} else {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
 	barbican "github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
@@ -15,13 +16,17 @@ import (
 func GetAPIVolumesAndMounts(instance *barbicanv1beta1.BarbicanAPI) ([]corev1.Volume, []corev1.VolumeMount, error) {
 	apiVolumes := []corev1.Volume{
 		barbican.GetCustomConfigVolume(instance.Name),
-		barbican.GetLogVolume(),
 	}
 
 	apiVolumeMounts := []corev1.VolumeMount{
 		barbican.GetCustomConfigVolumeMount(),
 		barbican.GetKollaConfigVolumeMount(instance.Name),
-		barbican.GetLogVolumeMount(),
+	}
+
+	// the log volume is only needed when logging to a file
+	if !barbican.IsStdoutLogging(instance.Spec.Logging) {
+		apiVolumes = append(apiVolumes, barbican.GetLogVolume())
+		apiVolumeMounts = append(apiVolumeMounts, barbican.GetLogVolumeMount())
 	}
 
 	// prepend general config volumes and mounts
@@ -55,9 +60,29 @@ func GetAPIVolumesAndMounts(instance *barbicanv1beta1.BarbicanAPI) ([]corev1.Vol
 
 	// Add PKCS11 volumes
 	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) && instance.Spec.PKCS11 != nil {
-		apiVolumes = append(apiVolumes, barbican.GetHSMVolumes(*instance.Spec.PKCS11)...)
-		apiVolumeMounts = append(apiVolumeMounts, barbican.GetHSMVolumeMounts()...)
+		apiVolumes = append(apiVolumes, barbican.GetHSMVolumes(*instance.Spec.PKCS11, barbican.GetOwningBarbicanName(instance))...)
+		apiVolumeMounts = append(apiVolumeMounts, barbican.GetHSMVolumeMounts(*instance.Spec.PKCS11)...)
+	}
+
+	// Add KMIP volumes
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreKMIP) && instance.Spec.KMIP != nil {
+		apiVolumes = append(apiVolumes, barbican.GetKMIPVolumes(*instance.Spec.KMIP)...)
+		apiVolumeMounts = append(apiVolumeMounts, barbican.GetKMIPVolumeMounts()...)
 	}
 
+	// Add Vault volumes
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) && instance.Spec.Vault != nil && instance.Spec.Vault.CASecret != "" {
+		apiVolumes = append(apiVolumes, barbican.GetVaultVolumes(*instance.Spec.Vault)...)
+		apiVolumeMounts = append(apiVolumeMounts, barbican.GetVaultVolumeMounts()...)
+	}
+
+	// Add the extraMounts propagated to the API
+	extraVolumes, extraVolumeMounts := barbican.GetExtraVolumesAndMounts(
+		instance.Spec.ExtraMounts,
+		[]storage.PropagationType{barbican.Barbican, barbican.BarbicanAPI},
+	)
+	apiVolumes = append(apiVolumes, extraVolumes...)
+	apiVolumeMounts = append(apiVolumeMounts, extraVolumeMounts...)
+
 	return apiVolumes, apiVolumeMounts, nil
 }
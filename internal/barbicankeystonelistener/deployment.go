@@ -2,6 +2,8 @@
 package barbicankeystonelistener
 
 import (
+	"slices"
+
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -24,11 +26,22 @@ func Deployment(
 	labels map[string]string,
 	annotations map[string]string,
 	topology *topologyv1.Topology,
+	transportURL string,
 ) *appsv1.Deployment {
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
 	args := []string{"-c", ServiceCommand}
+	//
+	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
+	//
+	// the keystone listener has no HTTP endpoint, its probes check that it runs and it is
+	// only ready while connected to RabbitMQ, so that a broker outage does not
+	// restart the pods
+	livenessProbe, readinessProbe, startupProbe := barbican.GetProbes(
+		barbican.GetServiceLivenessHandler(barbican.ServiceName+"-keystone-listener"),
+		barbican.GetServiceReadinessHandler(barbican.ServiceName+"-keystone-listener", transportURL),
+		instance.Spec.Probes)
 
 	keystoneListenerVolumes, keystoneListenerVolumeMounts := GetListenerVolumesAndMounts(instance)
 
@@ -82,6 +95,9 @@ func Deployment(
 							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
 							VolumeMounts:    keystoneListenerVolumeMounts,
 							Resources:       instance.Spec.Resources,
+							ReadinessProbe:  readinessProbe,
+							LivenessProbe:   livenessProbe,
+							StartupProbe:    startupProbe,
 						},
 					},
 				},
@@ -89,10 +105,20 @@ func Deployment(
 		},
 	}
 
+	// the service logs to stdout, there is no log file to stream
+	if barbican.IsStdoutLogging(instance.Spec.Logging) {
+		deployment.Spec.Template.Spec.Containers = slices.DeleteFunc(
+			deployment.Spec.Template.Spec.Containers,
+			func(c corev1.Container) bool { return c.Name == instance.Name+"-log" },
+		)
+	}
+
 	if instance.Spec.NodeSelector != nil {
 		deployment.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
 	}
 
+	barbican.ApplyRolloutStrategy(&deployment.Spec, instance.Spec.RolloutStrategy)
+
 	if topology != nil {
 		topology.ApplyTo(&deployment.Spec.Template)
 	} else {
@@ -3,6 +3,7 @@ package barbicankeystonelistener
 import (
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
 	barbican "github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -10,13 +11,17 @@ import (
 func GetListenerVolumesAndMounts(instance *barbicanv1beta1.BarbicanKeystoneListener) ([]corev1.Volume, []corev1.VolumeMount) {
 	listenerVolumes := []corev1.Volume{
 		barbican.GetCustomConfigVolume(instance.Name),
-		barbican.GetLogVolume(),
 	}
 
 	listenerVolumeMounts := []corev1.VolumeMount{
 		barbican.GetCustomConfigVolumeMount(),
 		barbican.GetKollaConfigVolumeMount(instance.Name),
-		barbican.GetLogVolumeMount(),
+	}
+
+	// the log volume is only needed when logging to a file
+	if !barbican.IsStdoutLogging(instance.Spec.Logging) {
+		listenerVolumes = append(listenerVolumes, barbican.GetLogVolume())
+		listenerVolumeMounts = append(listenerVolumeMounts, barbican.GetLogVolumeMount())
 	}
 
 	// prepend general config volumes and mounts
@@ -29,5 +34,13 @@ func GetListenerVolumesAndMounts(instance *barbicanv1beta1.BarbicanKeystoneListe
 		listenerVolumeMounts = append(listenerVolumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
 	}
 
+	// Add the extraMounts propagated to the keystone listener
+	extraVolumes, extraVolumeMounts := barbican.GetExtraVolumesAndMounts(
+		instance.Spec.ExtraMounts,
+		[]storage.PropagationType{barbican.Barbican, barbican.BarbicanKeystoneListener},
+	)
+	listenerVolumes = append(listenerVolumes, extraVolumes...)
+	listenerVolumeMounts = append(listenerVolumeMounts, extraVolumeMounts...)
+
 	return listenerVolumes, listenerVolumeMounts
 }
@@ -2,13 +2,13 @@
 package barbicanworker
 
 import (
+	"slices"
+
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	//"k8s.io/apimachinery/pkg/util/intstr"
-
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
 	barbican "github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
@@ -26,33 +26,25 @@ func Deployment(
 	labels map[string]string,
 	annotations map[string]string,
 	topology *topologyv1.Topology,
+	transportURL string,
 ) *appsv1.Deployment {
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
-	/*
-		livenessProbe := &corev1.Probe{
-			// TODO might need tuning
-			TimeoutSeconds:      5,
-			PeriodSeconds:       3,
-			InitialDelaySeconds: 5,
-		}
-		readinessProbe := &corev1.Probe{
-			// TODO might need tuning
-			TimeoutSeconds:      5,
-			PeriodSeconds:       5,
-			InitialDelaySeconds: 5,
-		}
-	*/
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) {
+		barbican.GetHSMVendorEnvVars(instance.Spec.PKCS11, envVars)
+	}
 	args := []string{"-c", ServiceCommand}
 	//
 	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
 	//
-	//livenessProbe.HTTPGet = &corev1.HTTPGetAction{
-	//	Path: "/healthcheck",
-	//	Port: intstr.IntOrString{Type: intstr.Int, IntVal: int32(barbican.BarbicanPublicPort)},
-	//}
-	//readinessProbe.HTTPGet = livenessProbe.HTTPGet
+	// the worker has no HTTP endpoint, its probes check that it runs and it is
+	// only ready while connected to RabbitMQ, so that a broker outage does not
+	// restart the pods
+	livenessProbe, readinessProbe, startupProbe := barbican.GetProbes(
+		barbican.GetServiceLivenessHandler(barbican.ServiceName+"-worker"),
+		barbican.GetServiceReadinessHandler(barbican.ServiceName+"-worker", transportURL),
+		instance.Spec.Probes)
 
 	workerVolumes, workerVolumeMounts := GetWorkerVolumesAndMounts(instance)
 
@@ -94,8 +86,6 @@ func Deployment(
 							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
 							VolumeMounts:    []corev1.VolumeMount{barbican.GetLogVolumeMount()},
 							Resources:       instance.Spec.Resources,
-							// ReadinessProbe: readinessProbe,
-							// LivenessProbe:  livenessProbe,
 						},
 						{
 							Name: barbican.ServiceName + "-worker",
@@ -108,8 +98,9 @@ func Deployment(
 							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
 							VolumeMounts:    workerVolumeMounts,
 							Resources:       instance.Spec.Resources,
-							// ReadinessProbe: readinessProbe,
-							// LivenessProbe:  livenessProbe,
+							ReadinessProbe:  readinessProbe,
+							LivenessProbe:   livenessProbe,
+							StartupProbe:    startupProbe,
 						},
 					},
 				},
@@ -117,10 +108,20 @@ func Deployment(
 		},
 	}
 
+	// the service logs to stdout, there is no log file to stream
+	if barbican.IsStdoutLogging(instance.Spec.Logging) {
+		deployment.Spec.Template.Spec.Containers = slices.DeleteFunc(
+			deployment.Spec.Template.Spec.Containers,
+			func(c corev1.Container) bool { return c.Name == instance.Name+"-log" },
+		)
+	}
+
 	if instance.Spec.NodeSelector != nil {
 		deployment.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
 	}
 
+	barbican.ApplyRolloutStrategy(&deployment.Spec, instance.Spec.RolloutStrategy)
+
 	if topology != nil {
 		topology.ApplyTo(&deployment.Spec.Template)
 	} else {
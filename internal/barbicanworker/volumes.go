@@ -5,6 +5,7 @@ import (
 
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
 	barbican "github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -12,13 +13,17 @@ import (
 func GetWorkerVolumesAndMounts(instance *barbicanv1beta1.BarbicanWorker) ([]corev1.Volume, []corev1.VolumeMount) {
 	workerVolumes := []corev1.Volume{
 		barbican.GetCustomConfigVolume(instance.Name),
-		barbican.GetLogVolume(),
 	}
 
 	workerVolumeMounts := []corev1.VolumeMount{
 		barbican.GetCustomConfigVolumeMount(),
 		barbican.GetKollaConfigVolumeMount(instance.Name),
-		barbican.GetLogVolumeMount(),
+	}
+
+	// the log volume is only needed when logging to a file
+	if !barbican.IsStdoutLogging(instance.Spec.Logging) {
+		workerVolumes = append(workerVolumes, barbican.GetLogVolume())
+		workerVolumeMounts = append(workerVolumeMounts, barbican.GetLogVolumeMount())
 	}
 
 	// prepend general config volumes and mounts
@@ -33,9 +38,29 @@ func GetWorkerVolumesAndMounts(instance *barbicanv1beta1.BarbicanWorker) ([]core
 
 	// Add PKCS11 volumes
 	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) && instance.Spec.PKCS11 != nil {
-		workerVolumes = append(workerVolumes, barbican.GetHSMVolumes(*instance.Spec.PKCS11)...)
-		workerVolumeMounts = append(workerVolumeMounts, barbican.GetHSMVolumeMounts()...)
+		workerVolumes = append(workerVolumes, barbican.GetHSMVolumes(*instance.Spec.PKCS11, barbican.GetOwningBarbicanName(instance))...)
+		workerVolumeMounts = append(workerVolumeMounts, barbican.GetHSMVolumeMounts(*instance.Spec.PKCS11)...)
+	}
+
+	// Add KMIP volumes
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreKMIP) && instance.Spec.KMIP != nil {
+		workerVolumes = append(workerVolumes, barbican.GetKMIPVolumes(*instance.Spec.KMIP)...)
+		workerVolumeMounts = append(workerVolumeMounts, barbican.GetKMIPVolumeMounts()...)
 	}
 
+	// Add Vault volumes
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) && instance.Spec.Vault != nil && instance.Spec.Vault.CASecret != "" {
+		workerVolumes = append(workerVolumes, barbican.GetVaultVolumes(*instance.Spec.Vault)...)
+		workerVolumeMounts = append(workerVolumeMounts, barbican.GetVaultVolumeMounts()...)
+	}
+
+	// Add the extraMounts propagated to the worker
+	extraVolumes, extraVolumeMounts := barbican.GetExtraVolumesAndMounts(
+		instance.Spec.ExtraMounts,
+		[]storage.PropagationType{barbican.Barbican, barbican.BarbicanWorker},
+	)
+	workerVolumes = append(workerVolumes, extraVolumes...)
+	workerVolumeMounts = append(workerVolumeMounts, extraVolumeMounts...)
+
 	return workerVolumes, workerVolumeMounts
 }
@@ -21,24 +21,53 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/deployment"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pdb"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // Static errors for Application Credential handling
 var (
-	ErrACSecretNotFound    = errors.New("ApplicationCredential secret not found")
-	ErrACSecretMissingKeys = errors.New("ApplicationCredential secret missing required keys")
+	ErrACSecretNotFound      = errors.New("ApplicationCredential secret not found")
+	ErrACSecretMissingKeys   = errors.New("ApplicationCredential secret missing required keys")
+	ErrProjectSecretStoresAC = errors.New("project secret stores need a token scoped to each project, ApplicationCredentials are scoped to the service project")
+)
+
+// Static errors for the database of Barbican
+var (
+	ErrExternalDatabaseMissing = errors.New("externalDatabase is required for the database type")
+)
+
+// Static errors for the backup and restore of Barbican
+var (
+	ErrBackupKeyMissing     = errors.New("backup key missing")
+	ErrBackupKEKMissing     = errors.New("no KEK in the KEK Secret")
+	ErrRestoreKEKMissing    = errors.New("no KEK Secret reported by the restore Job")
+	ErrRestoreKEKSecretDiff = errors.New("KEK Secret exists with different data")
+	ErrBackupDatabaseType   = errors.New("backup and restore only support a mariadb database")
 )
 
 type conditionUpdater interface {
@@ -91,6 +120,149 @@ func ensureTopology(
 	return topology, nil
 }
 
+// getAutoscaledReplicas - returns the replicas of the Deployment of an
+// autoscaled service: the ones set by its HorizontalPodAutoscaler, so that
+// the controller does not scale it back, or minReplicas when the Deployment
+// does not exist yet
+func getAutoscaledReplicas(
+	ctx context.Context,
+	h *helper.Helper,
+	name string,
+	namespace string,
+	autoscaling *barbicanv1beta1.BarbicanAutoscalingTemplate,
+) (*int32, error) {
+	depl, err := deployment.GetDeploymentWithName(ctx, h, name, namespace)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil && depl.Spec.Replicas != nil {
+		return depl.Spec.Replicas, nil
+	}
+	if autoscaling.MinReplicas != nil {
+		return autoscaling.MinReplicas, nil
+	}
+	return ptr.To[int32](1), nil
+}
+
+// reconcileHorizontalPodAutoscaler - creates or updates the
+// HorizontalPodAutoscaler of the Deployment of a service, or deletes it when
+// the service is not autoscaled
+func reconcileHorizontalPodAutoscaler(
+	ctx context.Context,
+	h *helper.Helper,
+	name string,
+	namespace string,
+	labels map[string]string,
+	autoscaling *barbicanv1beta1.BarbicanAutoscalingTemplate,
+) error {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	if autoscaling == nil {
+		err := h.GetClient().Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, hpa)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		h.GetLogger().Info(fmt.Sprintf("Deleting HorizontalPodAutoscaler %s, autoscaling is disabled", name))
+		return client.IgnoreNotFound(h.GetClient().Delete(ctx, hpa))
+	}
+
+	desired := barbican.HorizontalPodAutoscaler(name, namespace, labels, autoscaling)
+	hpa.Name = name
+	hpa.Namespace = namespace
+	op, err := controllerutil.CreateOrPatch(ctx, h.GetClient(), hpa, func() error {
+		hpa.Labels = util.MergeStringMaps(hpa.Labels, desired.Labels)
+		hpa.Spec = desired.Spec
+		return controllerutil.SetControllerReference(h.GetBeforeObject(), hpa, h.GetScheme())
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		h.GetLogger().Info(fmt.Sprintf("HorizontalPodAutoscaler %s - %s", name, op))
+	}
+	return nil
+}
+
+// reconcilePodDisruptionBudget - creates or updates the PodDisruptionBudget
+// of the pods of a service and reports it in the PDBReady condition
+func reconcilePodDisruptionBudget(
+	ctx context.Context,
+	h *helper.Helper,
+	conditions *condition.Conditions,
+	name string,
+	namespace string,
+	labels map[string]string,
+	replicas *int32,
+	budget *barbicanv1beta1.BarbicanPodDisruptionBudgetTemplate,
+) (ctrl.Result, error) {
+	pdbDef := barbican.PodDisruptionBudget(name, namespace, labels, ptr.Deref(replicas, 1), budget)
+	ctrlResult, err := pdb.NewPDB(pdbDef, time.Duration(5)*time.Second).CreateOrPatch(ctx, h)
+	if err != nil {
+		conditions.Set(condition.FalseCondition(
+			condition.PDBReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.PDBReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		conditions.Set(condition.FalseCondition(
+			condition.PDBReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			condition.PDBReadyInitMessage))
+		return ctrlResult, nil
+	}
+	conditions.MarkTrue(condition.PDBReadyCondition, condition.PDBReadyMessage)
+	return ctrl.Result{}, nil
+}
+
+// setRolloutCondition - reports the rollout of the Deployment of a service in
+// the BarbicanRolloutReady condition, with the reason of the ReplicaSet when
+// the rollout is stalled
+func setRolloutCondition(conditions *condition.Conditions, deploy appsv1.Deployment) {
+	reason, message, stalled := barbican.GetStalledRollout(deploy)
+	switch {
+	case stalled:
+		conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRolloutReadyCondition,
+			condition.Reason(reason),
+			condition.SeverityError,
+			barbicanv1beta1.BarbicanRolloutReadyStalledMessage,
+			message))
+	case barbican.IsRolledOut(deploy):
+		conditions.MarkTrue(barbicanv1beta1.BarbicanRolloutReadyCondition, barbicanv1beta1.BarbicanRolloutReadyMessage)
+	case deploy.Spec.Paused:
+		conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRolloutReadyCondition,
+			barbicanv1beta1.BarbicanRolloutPausedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanRolloutReadyPausedMessage))
+	default:
+		conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRolloutReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanRolloutReadyRunningMessage))
+	}
+}
+
+// serviceAdditionalTemplates are the templates shared by the services, they
+// are rendered in the config-data Secret of each service
+var serviceAdditionalTemplates = map[string]string{
+	barbican.LoggingConfigFileName: "/common/config/" + barbican.LoggingConfigFileName,
+}
+
+// configOverwriteFiles - returns the sorted names of the files the kolla
+// config of a service copies to barbican.ConfigOverwriteDir: the logging.conf
+// rendered for the service and the files of the defaultConfigOverwrites
+func configOverwriteFiles(overwrites ...map[string]string) []string {
+	files := map[string]string{barbican.LoggingConfigFileName: ""}
+	for _, overwrite := range overwrites {
+		maps.Copy(files, overwrite)
+	}
+	return slices.Sorted(maps.Keys(files))
+}
+
 // GenerateConfigsGeneric - generates config files
 func GenerateConfigsGeneric(
 	ctx context.Context, h *helper.Helper,
@@ -98,19 +270,21 @@ func GenerateConfigsGeneric(
 	envVars *map[string]env.Setter,
 	templateParameters map[string]any,
 	customData map[string]string,
+	additionalTemplates map[string]string,
 	cmLabels map[string]string,
 	scripts bool,
 ) error {
 	cms := []util.Template{
 		// Templates where the BarbicanAPI config is stored
 		{
-			Name:          fmt.Sprintf("%s-config-data", instance.GetName()),
-			Namespace:     instance.GetNamespace(),
-			Type:          util.TemplateTypeConfig,
-			InstanceType:  instance.GetObjectKind().GroupVersionKind().Kind,
-			ConfigOptions: templateParameters,
-			CustomData:    customData,
-			Labels:        cmLabels,
+			Name:               fmt.Sprintf("%s-config-data", instance.GetName()),
+			Namespace:          instance.GetNamespace(),
+			Type:               util.TemplateTypeConfig,
+			InstanceType:       instance.GetObjectKind().GroupVersionKind().Kind,
+			ConfigOptions:      templateParameters,
+			CustomData:         customData,
+			AdditionalTemplate: additionalTemplates,
+			Labels:             cmLabels,
 		},
 	}
 	if scripts {
@@ -153,3 +327,44 @@ func GenerateSecretStoreTemplateMap(
 	}
 	return tempMap, nil
 }
+
+// getJobTerminationMessage - returns the termination message of the last
+// container of the Job that terminated, empty when there is none. The message
+// is gone once DoJob deleted the finished Job, so it is read before.
+func getJobTerminationMessage(
+	ctx context.Context,
+	c client.Client,
+	jobDef *batchv1.Job,
+) (string, error) {
+	actualJob := &batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Name: jobDef.Name, Namespace: jobDef.Namespace}, actualJob)
+	if k8s_errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	pods := &corev1.PodList{}
+	err = c.List(ctx, pods, client.InNamespace(jobDef.Namespace),
+		client.MatchingLabels{batchv1.ControllerUidLabel: string(actualJob.UID)})
+	if err != nil {
+		return "", err
+	}
+
+	var last *corev1.ContainerStateTerminated
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			for _, terminated := range []*corev1.ContainerStateTerminated{
+				status.State.Terminated, status.LastTerminationState.Terminated,
+			} {
+				if terminated != nil && (last == nil || last.FinishedAt.Before(&terminated.FinishedAt)) {
+					last = terminated
+				}
+			}
+		}
+	}
+	if last == nil {
+		return "", nil
+	}
+	return last.Message, nil
+}
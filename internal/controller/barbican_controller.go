@@ -20,7 +20,10 @@ import (
 	"context"
 	"fmt"
 	maps0 "maps"
+	"net/http"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -44,6 +47,7 @@ import (
 	keystonev1 "github.com/openstack-k8s-operators/keystone-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/endpoint"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
@@ -80,6 +84,101 @@ const (
 	PKCS11PrepReadyRunningMessage = "PKCS11 Prep job is still running"
 	// PKCS11PrepReadyNotRunMessage is the message when PKCS11 prep job has not been run
 	PKCS11PrepReadyNotRunMessage = "PKCS11 Prep job not run"
+	// SoftHSMInitRunningMessage is the message when the SoftHSM token init job is still running
+	SoftHSMInitRunningMessage = "SoftHSM token init job is still running"
+	// SoftHSMInitErrorMessage is the error message template for SoftHSM token init failures
+	SoftHSMInitErrorMessage = "SoftHSM token init error occurred %s"
+	// SecretStoreMigrationReadyCondition indicates whether the requested secret store migration completed
+	SecretStoreMigrationReadyCondition = "SecretStoreMigrationReady"
+	// SecretStoreMigrationReadyInitMessage is the initial message for the secret store migration status
+	SecretStoreMigrationReadyInitMessage = "Secret store migration not started"
+	// SecretStoreMigrationReadyMessage is the message when the secret store migration completed
+	SecretStoreMigrationReadyMessage = "Secret store migration from %s to %s completed"
+	// SecretStoreMigrationReadyNotRequestedMessage is the message when no secret store migration is requested
+	SecretStoreMigrationReadyNotRequestedMessage = "Secret store migration not requested"
+	// SecretStoreMigrationReadyPendingMessage is the message while waiting for the services before the migration
+	SecretStoreMigrationReadyPendingMessage = "Secret store migration from %s to %s is waiting for the API and Worker"
+	// SecretStoreMigrationReadyRunningMessage is the message when the secret store migration job is still running
+	SecretStoreMigrationReadyRunningMessage = "Secret store migration from %s to %s is still running"
+	// SecretStoreMigrationReadyErrorMessage is the error message template for secret store migration failures
+	SecretStoreMigrationReadyErrorMessage = "Secret store migration error occurred %s"
+	// ProjectSecretStoresReadyCondition indicates whether the projects prefer the secret stores of the spec
+	ProjectSecretStoresReadyCondition = "ProjectSecretStoresReady"
+	// ProjectSecretStoresReadyInitMessage is the initial message for the project secret stores status
+	ProjectSecretStoresReadyInitMessage = "Project secret stores not checked"
+	// ProjectSecretStoresReadyMessage is the message when all the projects prefer the secret stores of the spec
+	ProjectSecretStoresReadyMessage = "Project secret stores in sync"
+	// ProjectSecretStoresReadyWaitingMessage is the message while the Barbican API is not ready
+	ProjectSecretStoresReadyWaitingMessage = "Project secret stores are waiting for the Barbican API"
+	// ProjectSecretStoresReadyPendingMessage is the message while projects are left for the next reconciliation
+	ProjectSecretStoresReadyPendingMessage = "Project secret stores in progress"
+	// ProjectSecretStoresReadyErrorMessage is the error message template for project secret store failures
+	ProjectSecretStoresReadyErrorMessage = "Project secret stores error occurred %s"
+	// MinorUpdateReadyCondition indicates whether the services run the container images of the spec
+	MinorUpdateReadyCondition = "MinorUpdateReady"
+	// MinorUpdateReadyInitMessage is the initial message for the minor update status
+	MinorUpdateReadyInitMessage = "Minor update not started"
+	// MinorUpdateReadyMessage is the message when the services run the container images of the spec
+	MinorUpdateReadyMessage = "Minor update completed"
+	// MinorUpdateReadyNotRequestedMessage is the message when the container images never changed
+	MinorUpdateReadyNotRequestedMessage = "Minor update not requested"
+	// MinorUpdateReadyRunningMessage is the message while a phase of the minor update is in progress
+	MinorUpdateReadyRunningMessage = "Minor update in phase %s"
+	// MinorUpdateReadyHaltedMessage is the error message template when a failed phase halted the minor update
+	MinorUpdateReadyHaltedMessage = "Minor update halted in phase %s: %s"
+	// DBPurgeReadyCondition indicates whether the last run of the database cleanup succeeded
+	DBPurgeReadyCondition = "DBPurgeReady"
+	// DBPurgeReadyInitMessage is the initial message for the database cleanup status
+	DBPurgeReadyInitMessage = "DB purge CronJob not created"
+	// DBPurgeReadyNotRequestedMessage is the message when no database cleanup is requested
+	DBPurgeReadyNotRequestedMessage = "DB purge not requested"
+	// DBPurgeReadyScheduledMessage is the message while the database cleanup did not run yet
+	DBPurgeReadyScheduledMessage = "DB purge scheduled"
+	// DBPurgeReadyMessage is the message when the last run of the database cleanup succeeded
+	DBPurgeReadyMessage = "DB purge job %s completed"
+	// DBPurgeReadyErrorMessage is the error message template for database cleanup failures
+	DBPurgeReadyErrorMessage = "DB purge error occurred %s"
+	// DBSchemaReadyCondition indicates whether the database is at the schema head of the API image
+	DBSchemaReadyCondition = "DBSchemaReady"
+	// DBSchemaReadyInitMessage is the initial message for the database schema status
+	DBSchemaReadyInitMessage = "Database schema revision not checked"
+	// DBSchemaReadyMessage is the message when the database is at the revision set by the last db-sync
+	DBSchemaReadyMessage = "Database schema at revision %s"
+	// DBSchemaReadyNotReportedMessage is the message when no db-sync reported the revision yet
+	DBSchemaReadyNotReportedMessage = "Database schema revision not reported"
+	// DBSchemaReadyNewerHeadMessage is the message when the image reports a newer schema head than the database revision
+	DBSchemaReadyNewerHeadMessage = "Image %s reports schema head %s, the database is at revision %s"
+	// DBSchemaReadyNotSyncedMessage is the message when the db-sync of a new image did not complete yet
+	DBSchemaReadyNotSyncedMessage = "Image %s is not synced yet, the database is at revision %s of image %s"
+	// DBBackupReadyCondition indicates whether the database was dumped before the db-sync of a new image
+	DBBackupReadyCondition = "DBBackupReady"
+	// DBBackupReadyInitMessage is the initial message for the database backup status
+	DBBackupReadyInitMessage = "DB backup not checked"
+	// DBBackupReadyMessage is the message when the database dump completed
+	DBBackupReadyMessage = "DB backup %s completed"
+	// DBBackupReadyNotRequestedMessage is the message when no database backup is requested
+	DBBackupReadyNotRequestedMessage = "DB backup not requested"
+	// DBBackupReadyNotNeededMessage is the message when the db-sync does not run for a new image
+	DBBackupReadyNotNeededMessage = "DB backup not needed"
+	// DBBackupReadyRunningMessage is the message while the database dump is running
+	DBBackupReadyRunningMessage = "DB backup %s is still running"
+	// DBBackupReadyErrorMessage is the error message template for database backup failures
+	DBBackupReadyErrorMessage = "DB backup error occurred %s"
+	// RestoreReadyCondition indicates whether a BarbicanRestore of the Barbican is pending
+	RestoreReadyCondition = "RestoreReady"
+	// RestoreReadyInitMessage is the initial message for the restore status
+	RestoreReadyInitMessage = "Restore not checked"
+	// RestoreReadyNotPendingMessage is the message when no restore of the Barbican is pending
+	RestoreReadyNotPendingMessage = "No restore pending"
+	// RestoreReadyWaitingKEKMessage is the message while the restore recreates the KEK Secret
+	RestoreReadyWaitingKEKMessage = "Waiting for BarbicanRestore %s to restore the KEK Secret"
+	// RestoreReadyWaitingDBMessage is the message while the restore loads the database dump
+	RestoreReadyWaitingDBMessage = "Waiting for BarbicanRestore %s to load the database dump"
+	// ProjectSecretStoresResyncInterval is the interval the project secret stores are checked for drift
+	ProjectSecretStoresResyncInterval = time.Duration(5) * time.Minute
+	// ProjectSecretStoresTimeout bounds the time a reconciliation spends on the
+	// project secret stores, the projects left are handled after a requeue
+	ProjectSecretStoresTimeout = time.Duration(15) * time.Second
 )
 
 // BarbicanReconciler reconciles a Barbican object
@@ -87,6 +186,9 @@ type BarbicanReconciler struct {
 	client.Client
 	Kclient kubernetes.Interface
 	Scheme  *runtime.Scheme
+	// SecretStoreClientTransport is used to reach the Keystone and Barbican
+	// APIs when managing the project secret stores, nil uses the default transport
+	SecretStoreClientTransport http.RoundTripper
 }
 
 // GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
@@ -110,12 +212,16 @@ func (r *BarbicanReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneservices,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneendpoints,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups="",resources=pods,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups=rabbitmq.openstack.org,resources=transporturls,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbaccounts/finalizers,verbs=update
 //+kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbdatabases,verbs=get;list;watch;create;update;patch;delete;
@@ -125,6 +231,7 @@ func (r *BarbicanReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanrestores,verbs=get;list;watch
 //+kubebuilder:rbac:groups="security.openshift.io",resourceNames=anyuid,resources=securitycontextconstraints,verbs=use
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -178,8 +285,9 @@ func (r *BarbicanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		condition.RestoreLastTransitionTimes(
 			&instance.Status.Conditions, savedConditions)
 		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			conditions := readyConditions(instance)
 			instance.Status.Conditions.Set(
-				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+				conditions.Mirror(condition.ReadyCondition))
 		}
 		err := helper.PatchInstance(ctx, instance)
 		if err != nil {
@@ -196,7 +304,14 @@ func (r *BarbicanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
 		condition.UnknownCondition(condition.DBReadyCondition, condition.InitReason, condition.DBReadyInitMessage),
 		condition.UnknownCondition(PKCS11PrepReadyCondition, condition.InitReason, PKCS11PrepReadyInitMessage),
+		condition.UnknownCondition(SecretStoreMigrationReadyCondition, condition.InitReason, SecretStoreMigrationReadyInitMessage),
+		condition.UnknownCondition(ProjectSecretStoresReadyCondition, condition.InitReason, ProjectSecretStoresReadyInitMessage),
+		condition.UnknownCondition(MinorUpdateReadyCondition, condition.InitReason, MinorUpdateReadyInitMessage),
+		condition.UnknownCondition(DBPurgeReadyCondition, condition.InitReason, DBPurgeReadyInitMessage),
+		condition.UnknownCondition(RestoreReadyCondition, condition.InitReason, RestoreReadyInitMessage),
+		condition.UnknownCondition(DBBackupReadyCondition, condition.InitReason, DBBackupReadyInitMessage),
 		condition.UnknownCondition(condition.DBSyncReadyCondition, condition.InitReason, condition.DBSyncReadyInitMessage),
+		condition.UnknownCondition(DBSchemaReadyCondition, condition.InitReason, DBSchemaReadyInitMessage),
 		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 		condition.UnknownCondition(condition.ServiceConfigReadyCondition, condition.InitReason, condition.ServiceConfigReadyInitMessage),
 		condition.UnknownCondition(barbicanv1beta1.BarbicanAPIReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanAPIReadyInitMessage),
@@ -217,6 +332,14 @@ func (r *BarbicanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		cl.Set(c)
 	}
 
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) {
+		c := condition.UnknownCondition(
+			barbicanv1beta1.BarbicanVaultInputReadyCondition,
+			condition.InitReason,
+			barbicanv1beta1.BarbicanVaultInputReadyInitMessage)
+		cl.Set(c)
+	}
+
 	instance.Status.Conditions.Init(&cl)
 
 	// If we're not deleting this and the service object doesn't have our finalizer, add it.
@@ -338,8 +461,36 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 		return ctrlResult, err
 	}
 
+	// a pending restore recreates the KEK Secret before it is checked, or
+	// generated
+	restore, err := r.getPendingRestore(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if restore != nil && !restore.IsKEKRestored() {
+		Log.Info(fmt.Sprintf("Service '%s' - waiting for BarbicanRestore %s to restore the KEK Secret", instance.Name, restore.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			RestoreReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			RestoreReadyWaitingKEKMessage,
+			restore.Name))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+
 	// check for Simple Crypto Backend secret holding the KEK
 	if len(instance.Spec.EnabledSecretStores) == 0 || slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreSimpleCrypto) {
+		err = r.ensureSimpleCryptoKEK(ctx, helper, instance, serviceLabels)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.InputReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+
 		fields := []string{instance.Spec.PasswordSelectors.SimpleCryptoKEK}
 		fields = append(fields, instance.Spec.PasswordSelectors.SimpleCryptoAdditionalKEKs...)
 		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.SimpleCryptoBackendSecret, fields, &configVars)
@@ -350,16 +501,75 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 
 	// check PKCS11 secrets
 	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) && instance.Spec.PKCS11 != nil {
+		loginFields := []string{instance.Spec.PasswordSelectors.PKCS11Pin}
+		if barbican.IsSoftHSM(instance.Spec.PKCS11) {
+			// the operator owns the softhsm token, so it generates its PINs
+			err = r.ensureSoftHSMLoginSecret(ctx, helper, instance, serviceLabels)
+			if err != nil {
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					condition.InputReadyCondition,
+					condition.ErrorReason,
+					condition.SeverityWarning,
+					condition.InputReadyErrorMessage,
+					err.Error()))
+				return ctrl.Result{}, err
+			}
+			loginFields = append(loginFields, barbican.SoftHSMSOPinKey)
+		}
+
 		// check pkcs11 login secret
-		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.LoginSecret, []string{instance.Spec.PasswordSelectors.PKCS11Pin}, &configVars)
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.LoginSecret, loginFields, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+
+		// check for PKCS11 secret holding the PKCS11 Client Data, a softhsm
+		// token does not need any
+		if instance.Spec.PKCS11.ClientDataSecret != "" {
+			ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.ClientDataSecret, []string{}, &configVars)
+			if err != nil {
+				return ctrlResult, err
+			}
+		}
+	}
+
+	// check KMIP secrets
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreKMIP) && instance.Spec.KMIP != nil {
+		// check for KMIP secret holding the client certificate and key
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.KMIP.ClientCertSecret, []string{barbican.KMIPClientCertKey, barbican.KMIPClientKeyKey}, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, nil
+		}
+
+		// check for KMIP secret holding the CA certificate
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.KMIP.CASecret, []string{barbican.KMIPCAKey}, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, nil
+		}
+	}
+
+	// check Vault secrets
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) && instance.Spec.Vault != nil {
+		ctrlResult, err = r.verifyVaultSecrets(ctx, helper, instance, &configVars)
 		if err != nil {
 			return ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, nil
 		}
+	}
 
-		// check for PKCS11 secret holding the PKCS11 Client Data
-		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.ClientDataSecret, []string{}, &configVars)
+	// check the credentials of the external DB
+	if instance.Spec.DatabaseType == barbicanv1beta1.DatabaseTypePostgreSQL && instance.Spec.ExternalDatabase != nil {
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.ExternalDatabase.Secret,
+			[]string{barbicanv1beta1.ExternalDatabaseUsernameSelector, barbicanv1beta1.ExternalDatabasePasswordSelector}, &configVars)
 		if err != nil {
 			return ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, nil
 		}
 	}
 
@@ -370,11 +580,20 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 	//
 	// create service DB instance
 	//
-	db, result, err := r.ensureDB(ctx, helper, instance)
-	if err != nil {
-		return ctrl.Result{}, err
-	} else if (result != ctrl.Result{}) {
-		return result, nil
+	var db *mariadbv1.Database
+	if instance.Spec.DatabaseType == barbicanv1beta1.DatabaseTypePostgreSQL {
+		err = r.ensureExternalDB(instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		var result ctrl.Result
+		db, result, err = r.ensureDB(ctx, helper, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		} else if (result != ctrl.Result{}) {
+			return result, nil
+		}
 	}
 	// create service DB - end
 
@@ -428,6 +647,13 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 			instance.Spec.BarbicanAPI.NetworkAttachments, err)
 	}
 
+	// start a minor update when the container images of the spec changed,
+	// reconcileInit runs the db-sync Job with the new API image first
+	err = r.startUpdate(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Handle service init
 	ctrlResult, err = r.reconcileInit(ctx, instance, helper, serviceLabels, serviceAnnotations)
 	if err != nil {
@@ -436,7 +662,11 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 		return ctrlResult, nil
 	}
 
-	// TODO(dmendiza): Handle service update
+	// Handle service update
+	err = r.reconcileUpdate(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// TODO(dmendiza): Handle service upgrade
 
@@ -460,6 +690,9 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 	if c != nil {
 		instance.Status.Conditions.Set(c)
 	}
+	// the ReadyCount of the services follows their Deployments, autoscaled
+	// ones included
+	instance.Status.BarbicanAPIReadyCount = barbicanAPI.Status.ReadyCount
 
 	// create or update Barbican Worker deployment
 	barbicanWorker, op, err := r.workerDeploymentCreateOrUpdate(ctx, instance, helper)
@@ -481,15 +714,18 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 	if c != nil {
 		instance.Status.Conditions.Set(c)
 	}
+	instance.Status.BarbicanWorkerReadyCount = barbicanWorker.Status.ReadyCount
 
 	// remove finalizers from unused MariaDBAccount records
 	// this assumes all database-depedendent deployments are up and
 	// running with current database account info
-	err = mariadbv1.DeleteUnusedMariaDBAccountFinalizers(
-		ctx, helper, barbican.DatabaseCRName,
-		instance.Spec.DatabaseAccount, instance.Namespace)
-	if err != nil {
-		return ctrl.Result{}, err
+	if instance.Spec.DatabaseType != barbicanv1beta1.DatabaseTypePostgreSQL {
+		err = mariadbv1.DeleteUnusedMariaDBAccountFinalizers(
+			ctx, helper, barbican.DatabaseCRName,
+			instance.Spec.DatabaseAccount, instance.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	// create or update Barbican KeystoneListener deployment
@@ -512,6 +748,43 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 	if c != nil {
 		instance.Status.Conditions.Set(c)
 	}
+	instance.Status.BarbicanKeystoneListenerReadyCount = barbicanKeystoneListener.Status.ReadyCount
+
+	// rewrap the Simple Crypto project KEKs if the active KEK changed
+	ctrlResult, err = r.reconcileSimpleCryptoKEKRotation(ctx, instance, helper, serviceLabels, serviceAnnotations)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	// rewrap the PKCS11 project KEKs if the MKEK or HMAC label changed
+	ctrlResult, err = r.reconcilePKCS11KeyRotation(ctx, instance, helper, serviceLabels, serviceAnnotations)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	// migrate the secrets to the target secret store before it becomes the global default
+	ctrlResult, err = r.reconcileSecretStoreMigration(ctx, instance, helper, serviceLabels, serviceAnnotations)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	// clean the soft-deleted entries of the database on a schedule
+	ctrlResult, err = r.reconcileDBPurge(ctx, instance, helper, serviceLabels, serviceAnnotations)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	// set the preferred secret store of the projects through the Barbican API,
+	// this is requeued periodically to detect drift
+	projectSecretStoresResult := r.reconcileProjectSecretStores(ctx, instance, helper, barbicanAPI)
 
 	// TODO(dmendiza): Handle API endpoints
 
@@ -521,27 +794,29 @@ func (r *BarbicanReconciler) reconcileNormal(ctx context.Context, instance *barb
 	instance.Status.ObservedGeneration = instance.Generation
 	// We reached the end of the Reconcile, update the Ready condition based on
 	// the sub conditions
-	if instance.Status.Conditions.AllSubConditionIsTrue() {
+	if conditions := readyConditions(instance); conditions.AllSubConditionIsTrue() {
 		instance.Status.Conditions.MarkTrue(
 			condition.ReadyCondition, condition.ReadyMessage)
 	}
-	return ctrl.Result{}, nil
+	return projectSecretStoresResult, nil
 }
 
 func (r *BarbicanReconciler) reconcileDelete(ctx context.Context, instance *barbicanv1beta1.Barbican, helper *helper.Helper) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' delete", instance.Name))
 
-	// remove db finalizer first
-	db, err := mariadbv1.GetDatabaseByNameAndAccount(ctx, helper, barbican.DatabaseCRName, instance.Spec.DatabaseAccount, instance.Namespace)
-	if err != nil && !k8s_errors.IsNotFound(err) {
-		return ctrl.Result{}, err
-	}
-
-	if !k8s_errors.IsNotFound(err) {
-		if err := db.DeleteFinalizer(ctx, helper); err != nil {
+	// remove db finalizer first, an external DB has none
+	if instance.Spec.DatabaseType != barbicanv1beta1.DatabaseTypePostgreSQL {
+		db, err := mariadbv1.GetDatabaseByNameAndAccount(ctx, helper, barbican.DatabaseCRName, instance.Spec.DatabaseAccount, instance.Namespace)
+		if err != nil && !k8s_errors.IsNotFound(err) {
 			return ctrl.Result{}, err
 		}
+
+		if !k8s_errors.IsNotFound(err) {
+			if err := db.DeleteFinalizer(ctx, helper); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 	}
 
 	// Remove the finalizer from our KeystoneService CR
@@ -560,6 +835,12 @@ func (r *BarbicanReconciler) reconcileDelete(ctx context.Context, instance *barb
 		}
 	}
 
+	// keep a Simple Crypto KEK generated by the operator, it is still needed
+	// to decrypt the secrets stored in the database
+	if err := r.retainSimpleCryptoKEK(ctx, helper, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Remove finalizers from any existing child BarbicanAPIs
 	barbicanAPI := &barbicanv1beta1.BarbicanAPI{}
 	err = r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-api", instance.Name), Namespace: instance.Namespace}, barbicanAPI)
@@ -627,10 +908,15 @@ const (
 	tlsAPIPublicField                   = ".spec.tls.api.public.secretName"
 	pkcs11LoginSecretField              = ".spec.pkcs11.loginSecret"      // #nosec G101
 	pkcs11ClientDataSecretField         = ".spec.pkcs11.clientDataSecret" // #nosec G101
+	kmipClientCertSecretField           = ".spec.kmip.clientCertSecret"   // #nosec G101
+	kmipCASecretField                   = ".spec.kmip.caSecret"           // #nosec G101
+	vaultAuthSecretField                = ".spec.vault.authSecret"        // #nosec G101
+	vaultCASecretField                  = ".spec.vault.caSecret"          // #nosec G101
 	topologyField                       = ".spec.topologyRef.Name"
 	customServiceConfigSecretsField     = ".spec.customServiceConfigSecrets" // #nosec G101
 	parentBarbicanConfigDataSecretField = ".status.parentBarbicanConfigDataSecret"
 	authAppCredSecretField              = ".spec.auth.applicationCredentialSecret" // #nosec G101
+	externalDatabaseSecretField         = ".spec.externalDatabase.secret"          // #nosec G101
 )
 
 var (
@@ -640,6 +926,10 @@ var (
 		caBundleSecretNameField,
 		pkcs11LoginSecretField,
 		pkcs11ClientDataSecretField,
+		kmipClientCertSecretField,
+		kmipCASecretField,
+		vaultAuthSecretField,
+		vaultCASecretField,
 		topologyField,
 		customServiceConfigSecretsField,
 		parentBarbicanConfigDataSecretField,
@@ -652,6 +942,10 @@ var (
 		tlsAPIPublicField,
 		pkcs11LoginSecretField,
 		pkcs11ClientDataSecretField,
+		kmipClientCertSecretField,
+		kmipCASecretField,
+		vaultAuthSecretField,
+		vaultCASecretField,
 		topologyField,
 		customServiceConfigSecretsField,
 		parentBarbicanConfigDataSecretField,
@@ -680,6 +974,66 @@ func (r *BarbicanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	// index externalDatabaseSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.Barbican{}, externalDatabaseSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.Barbican)
+		if cr.Spec.ExternalDatabase == nil || cr.Spec.ExternalDatabase.Secret == "" {
+			return nil
+		}
+		return []string{cr.Spec.ExternalDatabase.Secret}
+	}); err != nil {
+		return err
+	}
+
+	// index kmipClientCertSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.Barbican{}, kmipClientCertSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.Barbican)
+		if cr.Spec.KMIP == nil || cr.Spec.KMIP.ClientCertSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KMIP.ClientCertSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index kmipCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.Barbican{}, kmipCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.Barbican)
+		if cr.Spec.KMIP == nil || cr.Spec.KMIP.CASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KMIP.CASecret}
+	}); err != nil {
+		return err
+	}
+
+	// index vaultAuthSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.Barbican{}, vaultAuthSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.Barbican)
+		if cr.Spec.Vault == nil || cr.Spec.Vault.AuthSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Vault.AuthSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index vaultCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.Barbican{}, vaultCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.Barbican)
+		if cr.Spec.Vault == nil || cr.Spec.Vault.CASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Vault.CASecret}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&barbicanv1beta1.Barbican{}).
 		Owns(&barbicanv1beta1.BarbicanAPI{}).
@@ -691,10 +1045,13 @@ func (r *BarbicanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&keystonev1.KeystoneService{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&batchv1.Job{}).
+		Owns(&batchv1.CronJob{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
+		Watches(&barbicanv1beta1.BarbicanRestore{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectForRestore)).
 		Watches(&keystonev1.KeystoneAPI{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectForSrc),
 			builder.WithPredicates(keystonev1.KeystoneAPIStatusChangedPredicate)).
@@ -704,6 +1061,21 @@ func (r *BarbicanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// findObjectForRestore reconciles the Barbican of a BarbicanRestore, so that
+// it moves on as soon as the restore progressed
+func (r *BarbicanReconciler) findObjectForRestore(_ context.Context, src client.Object) []reconcile.Request {
+	restore, ok := src.(*barbicanv1beta1.BarbicanRestore)
+	if !ok {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      restore.Spec.BarbicanName,
+			Namespace: restore.Namespace,
+		},
+	}}
+}
+
 func (r *BarbicanReconciler) findObjectForSrc(ctx context.Context, src client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 
@@ -746,8 +1118,13 @@ func (r *BarbicanReconciler) findObjectsForSrc(ctx context.Context, src client.O
 		caBundleSecretNameField,
 		pkcs11LoginSecretField,
 		pkcs11ClientDataSecretField,
+		kmipClientCertSecretField,
+		kmipCASecretField,
+		vaultAuthSecretField,
+		vaultCASecretField,
 		customServiceConfigSecretsField,
 		authAppCredSecretField,
+		externalDatabaseSecretField,
 	} {
 		crList := &barbicanv1beta1.BarbicanList{}
 		listOps := &client.ListOptions{
@@ -806,9 +1183,31 @@ func (r *BarbicanReconciler) generateServiceConfig(
 	if instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
 		tlsCfg = &tls.Service{}
 	}
+
+	// the services mount my.cnf whatever the type of the DB, it is empty
+	// for an external DB
+	var databaseClientConfig string
+	var databaseConnection string
+	if db != nil {
+		databaseClientConfig = db.GetDatabaseClientConfig(tlsCfg) //(mschuppert) for now just get the default my.cnf
+		databaseAccount := db.GetAccount()
+		databaseSecret := db.GetSecret()
+		databaseConnection = fmt.Sprintf("mysql+pymysql://%s:%s@%s/%s?read_default_file=/etc/my.cnf",
+			databaseAccount.Spec.UserName,
+			string(databaseSecret.Data[mariadbv1.DatabasePasswordSelector]),
+			instance.Status.DatabaseHostname,
+			barbican.DatabaseName,
+		)
+	} else {
+		databaseConnection, err = r.getExternalDatabaseConnection(ctx, h, instance)
+		if err != nil {
+			return err
+		}
+	}
+
 	customData := map[string]string{
 		barbican.CustomConfigFileName: instance.Spec.CustomServiceConfig,
-		"my.cnf":                      db.GetDatabaseClientConfig(tlsCfg), //(mschuppert) for now just get the default my.cnf
+		"my.cnf":                      databaseClientConfig,
 	}
 
 	maps0.Copy(customData, instance.Spec.DefaultConfigOverwrite)
@@ -822,25 +1221,20 @@ func (r *BarbicanReconciler) generateServiceConfig(
 		return err
 	}
 
-	databaseAccount := db.GetAccount()
-	databaseSecret := db.GetSecret()
-
 	templateParameters := map[string]any{
-		"DatabaseConnection": fmt.Sprintf("mysql+pymysql://%s:%s@%s/%s?read_default_file=/etc/my.cnf",
-			databaseAccount.Spec.UserName,
-			string(databaseSecret.Data[mariadbv1.DatabasePasswordSelector]),
-			instance.Status.DatabaseHostname,
-			barbican.DatabaseName,
-		),
-		"KeystoneAuthURL":  keystoneInternalURL,
-		"ServicePassword":  string(ospSecret.Data[instance.Spec.PasswordSelectors.Service]),
-		"ServiceUser":      instance.Spec.ServiceUser,
-		"TransportURL":     transportURLSecretData,
-		"LogFile":          fmt.Sprintf("%s%s.log", barbican.BarbicanLogPath, instance.Name),
-		"EnableSecureRBAC": instance.Spec.BarbicanAPI.EnableSecureRBAC,
-		"Region":           keystoneAPI.GetRegion(),
+		"DatabaseConnection": databaseConnection,
+		"KeystoneAuthURL":    keystoneInternalURL,
+		"ServicePassword":    string(ospSecret.Data[instance.Spec.PasswordSelectors.Service]),
+		"ServiceUser":        instance.Spec.ServiceUser,
+		"TransportURL":       transportURLSecretData,
+		"LogFile":            fmt.Sprintf("%s%s.log", barbican.BarbicanLogPath, instance.Name),
+		"EnableSecureRBAC":   instance.Spec.BarbicanAPI.EnableSecureRBAC,
+		"Region":             keystoneAPI.GetRegion(),
 	}
 
+	// the logging options of the jobs, the services render their own
+	maps0.Copy(templateParameters, barbican.GetLoggingTemplateParameters(instance.Spec.Logging))
+
 	templateParameters["UseApplicationCredentials"] = false
 	// Retrieve Application Credential data if configured
 	// This AC data will be available to all Barbican components via the shared secret
@@ -872,6 +1266,11 @@ func (r *BarbicanReconciler) generateServiceConfig(
 	// To avoid a json parsing error in kolla files, we always need to set PKCS11ClientDataPath
 	// This gets overridden in the PKCS11 section below if needed.
 	templateParameters["PKCS11ClientDataPath"] = barbicanv1beta1.DefaultPKCS11ClientDataPath
+	// The scripts check the HSM vendor, only the client config template of
+	// the selected vendor is rendered
+	templateParameters["PKCS11Vendor"] = ""
+	var hsmVendorTemplates map[string]string
+	templateParameters["SoftHSMTokenPath"] = barbican.SoftHSMTokenMountPoint
 
 	// Set transportURL quorum queues
 	templateParameters["QuorumQueues"] = string(transportURLSecret.Data["quorumqueues"]) == "true"
@@ -888,13 +1287,17 @@ func (r *BarbicanReconciler) generateServiceConfig(
 	}
 
 	// Set secret store parameters
+	// the target of a secret store migration only becomes the global default
+	// once all the secrets have been migrated to it
+	globalDefaultSecretStore := getGlobalDefaultSecretStore(instance)
 	secretStoreTemplateMap, err := GenerateSecretStoreTemplateMap(
 		instance.Spec.EnabledSecretStores,
-		instance.Spec.GlobalDefaultSecretStore)
+		globalDefaultSecretStore)
 	if err != nil {
 		return err
 	}
 	maps.Copy(templateParameters, secretStoreTemplateMap)
+	instance.Status.GlobalDefaultSecretStore = secretStoreTemplateMap["GlobalDefaultSecretStore"].(barbicanv1beta1.SecretStore)
 
 	// Set pkcs11 parameters
 	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) && instance.Spec.PKCS11 != nil {
@@ -905,6 +1308,40 @@ func (r *BarbicanReconciler) generateServiceConfig(
 		templateParameters["PKCS11Login"] = string(hsmLoginSecret.Data[instance.Spec.PasswordSelectors.PKCS11Pin])
 		templateParameters["PKCS11Enabled"] = true
 		templateParameters["PKCS11ClientDataPath"] = instance.Spec.PKCS11.ClientDataPath
+		maps0.Copy(templateParameters, getPKCS11TemplateParameters(instance.Spec.PKCS11))
+		hsmVendorTemplates = barbican.GetHSMVendorTemplates(instance.Spec.PKCS11)
+	}
+
+	// Set kmip parameters
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreKMIP) && instance.Spec.KMIP != nil {
+		templateParameters["KMIPEnabled"] = true
+		templateParameters["KMIPHost"] = instance.Spec.KMIP.Host
+		templateParameters["KMIPPort"] = instance.Spec.KMIP.Port
+		templateParameters["KMIPCertFile"] = fmt.Sprintf("%s/client.crt", barbican.KMIPClientDataPath)
+		templateParameters["KMIPKeyFile"] = fmt.Sprintf("%s/client.key", barbican.KMIPClientDataPath)
+		templateParameters["KMIPCACerts"] = fmt.Sprintf("%s/ca.crt", barbican.KMIPClientDataPath)
+	}
+
+	// Set vault parameters
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) && instance.Spec.Vault != nil {
+		vaultAuthSecret, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.Vault.AuthSecret, instance.Namespace)
+		if err != nil {
+			return err
+		}
+		templateParameters["VaultEnabled"] = true
+		templateParameters["VaultURL"] = instance.Spec.Vault.URL
+		templateParameters["VaultKVMountpoint"] = instance.Spec.Vault.KVMountpoint
+		templateParameters["VaultNamespace"] = instance.Spec.Vault.Namespace
+		if instance.Spec.Vault.AuthMethod == barbicanv1beta1.VaultAuthMethodAppRole {
+			templateParameters["VaultRoleID"] = string(vaultAuthSecret.Data[barbican.VaultRoleIDKey])
+			templateParameters["VaultSecretID"] = string(vaultAuthSecret.Data[barbican.VaultSecretIDKey])
+		} else {
+			templateParameters["VaultRootToken"] = string(vaultAuthSecret.Data[barbican.VaultTokenKey])
+		}
+		templateParameters["VaultUseSSL"] = strings.HasPrefix(instance.Spec.Vault.URL, "https://")
+		if instance.Spec.Vault.CASecret != "" {
+			templateParameters["VaultCACertFile"] = fmt.Sprintf("%s/ca.crt", barbican.VaultCAPath)
+		}
 	}
 
 	// Set simpleCrypto parameters
@@ -913,8 +1350,8 @@ func (r *BarbicanReconciler) generateServiceConfig(
 		if err != nil {
 			return err
 		}
-		keks := []string{string(simpleCryptoSecret.Data[instance.Spec.PasswordSelectors.SimpleCryptoKEK])}
-		for _, secretField := range instance.Spec.PasswordSelectors.SimpleCryptoAdditionalKEKs {
+		keks := []string{}
+		for _, secretField := range getSimpleCryptoKEKSelectors(instance) {
 			keks = append(keks, string(simpleCryptoSecret.Data[secretField]))
 		}
 		templateParameters["SimpleCryptoKEKs"] = keks
@@ -934,9 +1371,22 @@ func (r *BarbicanReconciler) generateServiceConfig(
 		httpdVhostConfig[endpt.String()] = endptConfig
 	}
 	templateParameters["VHosts"] = httpdVhostConfig
+	templateParameters["APILogStdout"] = barbican.IsStdoutLogging(
+		barbican.MergeLogging(instance.Spec.Logging, instance.Spec.BarbicanAPI.Logging))
 	templateParameters["TimeOut"] = instance.Spec.APITimeout
-
-	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, labels, true)
+	templateParameters["WSGI"] = barbican.GetWSGITuning(
+		instance.Spec.BarbicanAPI.WSGI, instance.Spec.BarbicanAPI.Resources)
+
+	// the defaultConfigOverwrite files end up in the config-data Secret of
+	// each service, list them for the kolla config of the service
+	templateParameters["APIConfigOverwriteFiles"] = configOverwriteFiles(
+		instance.Spec.DefaultConfigOverwrite, instance.Spec.BarbicanAPI.DefaultConfigOverwrite)
+	templateParameters["WorkerConfigOverwriteFiles"] = configOverwriteFiles(
+		instance.Spec.DefaultConfigOverwrite, instance.Spec.BarbicanWorker.DefaultConfigOverwrite)
+	templateParameters["KeystoneListenerConfigOverwriteFiles"] = configOverwriteFiles(
+		instance.Spec.DefaultConfigOverwrite, instance.Spec.BarbicanKeystoneListener.DefaultConfigOverwrite)
+
+	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, hsmVendorTemplates, labels, true)
 }
 
 func (r *BarbicanReconciler) transportURLCreateOrUpdate(
@@ -990,6 +1440,12 @@ func (r *BarbicanReconciler) apiDeploymentCreateOrUpdate(ctx context.Context, in
 		TransportURLSecret:  instance.Status.TransportURLSecret,
 	}
 
+	// The logging of the Barbican API overrides the top-level one
+	apiSpec.Logging = barbican.MergeLogging(instance.Spec.Logging, instance.Spec.BarbicanAPI.Logging)
+
+	// During a minor update the Barbican API keeps its image until its phase starts
+	apiSpec.ContainerImage = barbican.GetContainerImages(instance).API
+
 	// If NodeSelector is not specified in BarbicanAPITemplate, the current
 	// API instance inherits the value from the top-level CR.
 	if apiSpec.NodeSelector == nil {
@@ -1047,6 +1503,12 @@ func (r *BarbicanReconciler) workerDeploymentCreateOrUpdate(ctx context.Context,
 		TLS:                    instance.Spec.BarbicanAPI.TLS.Ca,
 	}
 
+	// The logging of the Barbican Worker overrides the top-level one
+	workerSpec.Logging = barbican.MergeLogging(instance.Spec.Logging, instance.Spec.BarbicanWorker.Logging)
+
+	// During a minor update the Barbican Worker keeps its image until its phase starts
+	workerSpec.ContainerImage = barbican.GetContainerImages(instance).Worker
+
 	// If NodeSelector is not specified in BarbicanWorkerTemplate, the current
 	// Worker instance inherits the value from the top-level CR.
 	if workerSpec.NodeSelector == nil {
@@ -1100,6 +1562,12 @@ func (r *BarbicanReconciler) keystoneListenerDeploymentCreateOrUpdate(ctx contex
 		TLS:                              instance.Spec.BarbicanAPI.TLS.Ca,
 	}
 
+	// The logging of the Barbican Keystone Listener overrides the top-level one
+	keystoneListenerSpec.Logging = barbican.MergeLogging(instance.Spec.Logging, instance.Spec.BarbicanKeystoneListener.Logging)
+
+	// During a minor update the Barbican Keystone Listener keeps its image until its phase starts
+	keystoneListenerSpec.ContainerImage = barbican.GetContainerImages(instance).KeystoneListener
+
 	// If NodeSelector is not specified in BarbicanKeystoneListenerTemplate, the current
 	// KeystoneListener instance inherits the value from the top-level CR.
 	if keystoneListenerSpec.NodeSelector == nil {
@@ -1141,6 +1609,22 @@ func (r *BarbicanReconciler) keystoneListenerDeploymentCreateOrUpdate(ctx contex
 	return deployment, op, err
 }
 
+// barbicanRbacRules are the rules of the service account of the jobs and the
+// services
+var barbicanRbacRules = []rbacv1.PolicyRule{
+	{
+		APIGroups:     []string{"security.openshift.io"},
+		ResourceNames: []string{"anyuid"},
+		Resources:     []string{"securitycontextconstraints"},
+		Verbs:         []string{"use"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"create", "get", "list", "watch", "update", "patch", "delete"},
+	},
+}
+
 func (r *BarbicanReconciler) reconcileInit(
 	ctx context.Context,
 	instance *barbicanv1beta1.Barbican,
@@ -1152,20 +1636,7 @@ func (r *BarbicanReconciler) reconcileInit(
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' init", instance.Name))
 
 	// Service account, role, binding
-	rbacRules := []rbacv1.PolicyRule{
-		{
-			APIGroups:     []string{"security.openshift.io"},
-			ResourceNames: []string{"anyuid"},
-			Resources:     []string{"securitycontextconstraints"},
-			Verbs:         []string{"use"},
-		},
-		{
-			APIGroups: []string{""},
-			Resources: []string{"pods"},
-			Verbs:     []string{"create", "get", "list", "watch", "update", "patch", "delete"},
-		},
-	}
-	rbacResult, err := common_rbac.ReconcileRbac(ctx, helper, instance, rbacRules)
+	rbacResult, err := common_rbac.ReconcileRbac(ctx, helper, instance, barbicanRbacRules)
 	if err != nil {
 		return rbacResult, err
 	} else if (rbacResult != ctrl.Result{}) {
@@ -1217,12 +1688,53 @@ func (r *BarbicanReconciler) reconcileInit(
 		instance.Status.Hash = map[string]string{}
 	}
 
+	//
+	// wait for a pending restore to load the database dump before the db-sync
+	//
+	restore, err := r.getPendingRestore(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if restore != nil {
+		Log.Info(fmt.Sprintf("Service '%s' - waiting for BarbicanRestore %s to load the database dump", instance.Name, restore.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			RestoreReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			RestoreReadyWaitingDBMessage,
+			restore.Name))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+	instance.Status.Conditions.MarkTrue(RestoreReadyCondition, RestoreReadyNotPendingMessage)
+
+	// a new image is flagged as soon as its db-sync is due, it may wait
+	// for the dump
+	setDBSchemaCondition(instance, "", false)
+
+	//
+	// dump the database before the db-sync of a new image
+	//
+	ctrlResult, err = r.reconcileDBBackup(ctx, instance, helper, serviceLabels, serviceAnnotations)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
 	//
 	// run Barbican db sync
 	//
 	dbSyncHash := instance.Status.Hash[barbicanv1beta1.DbSyncHash]
 	jobDef := barbican.DbSyncJob(instance, serviceLabels, serviceAnnotations)
 
+	// the termination message of the db-sync is read before DoJob deletes
+	// the finished Job
+	dbSyncMessage, err := getJobTerminationMessage(ctx, r.Client, jobDef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	schemaHead, schemaRevision := barbican.ParseDBSyncMessage(dbSyncMessage)
+
 	dbSyncjob := job.NewJob(
 		jobDef,
 		barbicanv1beta1.DbSyncHash,
@@ -1240,6 +1752,7 @@ func (r *BarbicanReconciler) reconcileInit(
 			condition.RequestedReason,
 			condition.SeverityInfo,
 			condition.DBSyncReadyRunningMessage))
+		setDBSchemaCondition(instance, schemaHead, false)
 		return ctrlResult, nil
 	}
 	if err != nil {
@@ -1249,13 +1762,40 @@ func (r *BarbicanReconciler) reconcileInit(
 			condition.SeverityWarning,
 			condition.DBSyncReadyErrorMessage,
 			err.Error()))
+		setDBSchemaCondition(instance, schemaHead, true)
 		return ctrl.Result{}, err
 	}
 	if dbSyncjob.HasChanged() {
 		instance.Status.Hash[barbicanv1beta1.DbSyncHash] = dbSyncjob.GetHash()
 		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.DbSyncHash]))
+
+		// a successful upgrade leaves the database at the head of the image,
+		// whether or not the revision could be read afterwards. The image is
+		// recorded even when neither was reported, the status must not claim
+		// the previous image.
+		if schemaRevision == "" {
+			schemaRevision = schemaHead
+		}
+		now := metav1.Now()
+		instance.Status.DatabaseSchema = &barbicanv1beta1.BarbicanDatabaseSchemaStatus{
+			Revision: schemaRevision,
+			Image:    jobDef.Spec.Template.Spec.Containers[0].Image,
+			SyncTime: &now,
+		}
+		Log.Info(fmt.Sprintf("Service '%s' - database schema at revision %s", instance.Name, schemaRevision))
 	}
 	instance.Status.Conditions.MarkTrue(condition.DBSyncReadyCondition, condition.DBSyncReadyMessage)
+	setDBSchemaCondition(instance, schemaHead, false)
+
+	//
+	// initialise the SoftHSM token before generating the keys in it
+	//
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) && barbican.IsSoftHSM(instance.Spec.PKCS11) {
+		ctrlResult, err = r.reconcileSoftHSMToken(ctx, instance, helper, serviceLabels, serviceAnnotations)
+		if (ctrlResult != ctrl.Result{}) || err != nil {
+			return ctrlResult, err
+		}
+	}
 
 	//
 	// run Barbican pkcs11-prep if needed
@@ -1310,33 +1850,412 @@ func (r *BarbicanReconciler) reconcileInit(
 	return ctrl.Result{}, nil
 }
 
-func (r *BarbicanReconciler) verifySecret(
+func (r *BarbicanReconciler) reconcileSimpleCryptoKEKRotation(
 	ctx context.Context,
-	h *helper.Helper,
 	instance *barbicanv1beta1.Barbican,
-	secretName string,
-	expectedFields []string,
-	envVars *map[string]env.Setter,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
 ) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
 
-	// All expectedFields are password fields, so we associate each with a
-	// password validator to ensure invalid detected patterns are rejected.
-	validateFields := map[string]oko_secret.Validator{}
-	for _, f := range expectedFields {
-		validateFields[f] = oko_secret.PasswordValidator{}
+	if len(instance.Spec.EnabledSecretStores) != 0 && !slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreSimpleCrypto) {
+		return ctrl.Result{}, nil
 	}
-	hash, result, err := oko_secret.VerifySecretFields(
-		ctx,
-		types.NamespacedName{Name: secretName, Namespace: instance.Namespace},
-		validateFields,
-		h.GetClient(),
-		time.Second*10)
+
+	activeKEK := instance.Spec.PasswordSelectors.GetSimpleCryptoActiveKEK()
+	rotation := instance.Status.SimpleCryptoKEKRotation
+	if rotation == nil || rotation.ActiveKEK == "" {
+		// no project KEK has been wrapped with another KEK yet, just record the active one
+		instance.Status.SimpleCryptoKEKRotation = &barbicanv1beta1.SimpleCryptoKEKRotationStatus{
+			ActiveKEK: activeKEK,
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if rotation.ActiveKEK == activeKEK {
+		// a pending rotation has been reverted in the spec
+		if rotation.PendingKEK != "" {
+			rotation.PendingKEK = ""
+			rotation.State = ""
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if rotation.PendingKEK != activeKEK {
+		// the config rendering the new active KEK first has just been
+		// generated, wait for the services to pick it up so that no new
+		// project KEK gets wrapped with the previous KEK during the rewrap
+		rotation.PendingKEK = activeKEK
+		rotation.State = barbicanv1beta1.SimpleCryptoKEKRotationPending
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+	rolledOut, err := r.isConfigRolledOut(ctx, helper, instance)
 	if err != nil {
-		instance.Status.Conditions.Set(condition.FalseCondition(
-			condition.InputReadyCondition,
-			condition.ErrorReason,
-			condition.SeverityWarning,
+		return ctrl.Result{}, err
+	}
+	if !rolledOut {
+		Log.Info(fmt.Sprintf("Service '%s' - waiting for API and Worker to roll out the config before rewrapping the Simple Crypto KEKs", instance.Name))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+	rotation.State = barbicanv1beta1.SimpleCryptoKEKRotationRunning
+
+	rewrapHash := instance.Status.Hash[barbicanv1beta1.SimpleCryptoKEKRewrapHash]
+	jobDef := barbican.SimpleCryptoKEKRewrapJob(instance, serviceLabels, serviceAnnotations, activeKEK)
+
+	rewrapJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.SimpleCryptoKEKRewrapHash,
+		instance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		rewrapHash,
+	)
+	ctrlResult, err := rewrapJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+	if err != nil {
+		rotation.State = barbicanv1beta1.SimpleCryptoKEKRotationFailed
+		return ctrl.Result{}, err
+	}
+	if rewrapJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.SimpleCryptoKEKRewrapHash] = rewrapJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.SimpleCryptoKEKRewrapHash]))
+	}
+
+	// all project KEKs are now wrapped with the new active KEK, so the
+	// previous one can be dropped from the rendered config
+	retiredKEK := rotation.ActiveKEK
+	rotation.RetiredKEKs = slices.DeleteFunc(rotation.RetiredKEKs, func(kek string) bool {
+		return kek == activeKEK
+	})
+	if !slices.Contains(rotation.RetiredKEKs, retiredKEK) {
+		rotation.RetiredKEKs = append(rotation.RetiredKEKs, retiredKEK)
+	}
+	rotation.ActiveKEK = activeKEK
+	rotation.PendingKEK = ""
+	rotation.State = barbicanv1beta1.SimpleCryptoKEKRotationCompleted
+	now := metav1.Now()
+	rotation.LastRotationTime = &now
+	Log.Info(fmt.Sprintf("Service '%s' - Simple Crypto KEK rotated from %s to %s", instance.Name, retiredKEK, activeKEK))
+
+	// requeue to render the service config without the retired KEK
+	return ctrl.Result{Requeue: true}, nil
+}
+
+func (r *BarbicanReconciler) reconcilePKCS11KeyRotation(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if !slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStorePKCS11) || instance.Spec.PKCS11 == nil {
+		return ctrl.Result{}, nil
+	}
+
+	mkekLabel := instance.Spec.PKCS11.MKEKLabel
+	hmacLabel := instance.Spec.PKCS11.HMACLabel
+	if mkekLabel == "" || hmacLabel == "" {
+		// the labels are managed in customServiceConfig
+		return ctrl.Result{}, nil
+	}
+
+	rotation := instance.Status.PKCS11KeyRotation
+	if rotation == nil || rotation.MKEKLabel == "" || rotation.HMACLabel == "" {
+		// the keys have just been generated by the prep job, just record the labels
+		instance.Status.PKCS11KeyRotation = &barbicanv1beta1.PKCS11KeyRotationStatus{
+			MKEKLabel: mkekLabel,
+			HMACLabel: hmacLabel,
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if rotation.MKEKLabel == mkekLabel && rotation.HMACLabel == hmacLabel {
+		// a pending rotation has been reverted in the spec
+		if rotation.PendingMKEKLabel != "" || rotation.PendingHMACLabel != "" {
+			rotation.PendingMKEKLabel = ""
+			rotation.PendingHMACLabel = ""
+			rotation.State = ""
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if rotation.PendingMKEKLabel != mkekLabel || rotation.PendingHMACLabel != hmacLabel {
+		// the prep job generated the new keys and the config rendering the
+		// new labels has just been generated, wait for the services to pick
+		// it up so that no new project KEK gets wrapped with the previous keys
+		rotation.PendingMKEKLabel = mkekLabel
+		rotation.PendingHMACLabel = hmacLabel
+		rotation.State = barbicanv1beta1.PKCS11KeyRotationPending
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+	rolledOut, err := r.isConfigRolledOut(ctx, helper, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !rolledOut {
+		Log.Info(fmt.Sprintf("Service '%s' - waiting for API and Worker to roll out the config before rewrapping the PKCS11 project KEKs", instance.Name))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+	rotation.State = barbicanv1beta1.PKCS11KeyRotationRunning
+
+	rewrapHash := instance.Status.Hash[barbicanv1beta1.PKCS11RewrapHash]
+	jobDef := barbican.PKCS11RewrapJob(instance, serviceLabels, serviceAnnotations)
+
+	rewrapJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.PKCS11RewrapHash,
+		instance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		rewrapHash,
+	)
+	ctrlResult, err := rewrapJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+	if err != nil {
+		rotation.State = barbicanv1beta1.PKCS11KeyRotationFailed
+		return ctrl.Result{}, err
+	}
+	if rewrapJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.PKCS11RewrapHash] = rewrapJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.PKCS11RewrapHash]))
+	}
+
+	Log.Info(fmt.Sprintf("Service '%s' - PKCS11 keys rotated from MKEK %s and HMAC %s to MKEK %s and HMAC %s",
+		instance.Name, rotation.MKEKLabel, rotation.HMACLabel, mkekLabel, hmacLabel))
+	rotation.MKEKLabel = mkekLabel
+	rotation.HMACLabel = hmacLabel
+	rotation.PendingMKEKLabel = ""
+	rotation.PendingHMACLabel = ""
+	rotation.State = barbicanv1beta1.PKCS11KeyRotationCompleted
+	now := metav1.Now()
+	rotation.LastRotationTime = &now
+
+	return ctrl.Result{}, nil
+}
+
+// getPKCS11TemplateParameters returns the typed p11_crypto_plugin settings to
+// render, options that are not set are left to customServiceConfig
+func getPKCS11TemplateParameters(pkcs11 *barbicanv1beta1.BarbicanPKCS11Template) map[string]any {
+	params := map[string]any{
+		"PKCS11LibraryPath":         pkcs11.LibraryPath,
+		"PKCS11TokenLabel":          pkcs11.TokenLabel,
+		"PKCS11MKEKLabel":           pkcs11.MKEKLabel,
+		"PKCS11HMACLabel":           pkcs11.HMACLabel,
+		"PKCS11EncryptionMechanism": pkcs11.EncryptionMechanism,
+		"PKCS11HMACKeyType":         pkcs11.HMACKeyType,
+		"PKCS11HMACKeygenMechanism": pkcs11.HMACKeygenMechanism,
+		"PKCS11HMACMechanism":       pkcs11.HMACMechanism,
+		"PKCS11KeyWrapMechanism":    pkcs11.KeyWrapMechanism,
+	}
+	if profile, ok := barbican.GetHSMVendorProfile(pkcs11.Vendor); ok {
+		params["PKCS11Vendor"] = string(pkcs11.Vendor)
+		params["PKCS11Servers"] = pkcs11.Servers
+		params["HSMVendorConfigFile"] = profile.ConfigFile
+		params["HSMVendorConfigPath"] = profile.ConfigPath
+		if pkcs11.LibraryPath == "" {
+			params["PKCS11LibraryPath"] = profile.LibraryPath
+		}
+	}
+	if barbican.IsSoftHSM(pkcs11) {
+		params["PKCS11TokenLabel"] = barbican.GetSoftHSMTokenLabel(pkcs11)
+	}
+	// numbers and booleans are rendered as strings so that zero values
+	// that have been set explicitly are not skipped by the template
+	if pkcs11.SlotID != nil {
+		params["PKCS11SlotID"] = strconv.Itoa(*pkcs11.SlotID)
+	}
+	if pkcs11.MKEKLength != 0 {
+		params["PKCS11MKEKLength"] = strconv.Itoa(pkcs11.MKEKLength)
+	}
+	if pkcs11.HMACLength != 0 {
+		params["PKCS11HMACLength"] = strconv.Itoa(pkcs11.HMACLength)
+	}
+	if pkcs11.AESGCMGenerateIV != nil {
+		params["PKCS11AESGCMGenerateIV"] = strconv.FormatBool(*pkcs11.AESGCMGenerateIV)
+	}
+	if pkcs11.OSLockingOK != nil {
+		params["PKCS11OSLockingOK"] = strconv.FormatBool(*pkcs11.OSLockingOK)
+	}
+	return params
+}
+
+// getSimpleCryptoKEKSelectors returns the fields of the SimpleCryptoBackendSecret
+// to render as Simple Crypto KEKs, starting with the active one
+func getSimpleCryptoKEKSelectors(instance *barbicanv1beta1.Barbican) []string {
+	activeKEK := instance.Spec.PasswordSelectors.GetSimpleCryptoActiveKEK()
+	selectors := []string{activeKEK}
+
+	rotation := instance.Status.SimpleCryptoKEKRotation
+	// keep the KEK being rotated out until the rewrap job succeeded
+	if rotation != nil && rotation.ActiveKEK != "" && rotation.ActiveKEK != activeKEK {
+		selectors = append(selectors, rotation.ActiveKEK)
+	}
+
+	candidates := []string{instance.Spec.PasswordSelectors.SimpleCryptoKEK}
+	candidates = append(candidates, instance.Spec.PasswordSelectors.SimpleCryptoAdditionalKEKs...)
+	for _, selector := range candidates {
+		if slices.Contains(selectors, selector) {
+			continue
+		}
+		if rotation != nil && slices.Contains(rotation.RetiredKEKs, selector) {
+			continue
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
+
+// ensureSimpleCryptoKEK creates the SimpleCryptoBackendSecret with a random KEK
+// when GenerateSimpleCryptoKEK is set and the Secret does not exist, and records
+// in the status whether the KEK was generated by the operator
+func (r *BarbicanReconciler) ensureSimpleCryptoKEK(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+	serviceLabels map[string]string,
+) error {
+	Log := r.GetLogger(ctx)
+
+	kekSecret, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.SimpleCryptoBackendSecret, instance.Namespace)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return err
+	}
+
+	if k8s_errors.IsNotFound(err) {
+		if !instance.Spec.GenerateSimpleCryptoKEK {
+			// verifySecret reports the missing Secret
+			instance.Status.SimpleCryptoKEKGenerated = false
+			instance.Status.SimpleCryptoKEKGenerationTime = nil
+			return nil
+		}
+
+		kek, err := barbican.GenerateSimpleCryptoKEK()
+		if err != nil {
+			return fmt.Errorf("error generating Simple Crypto KEK: %w", err)
+		}
+
+		kekSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Spec.SimpleCryptoBackendSecret,
+				Namespace: instance.Namespace,
+				Labels:    serviceLabels,
+				Annotations: map[string]string{
+					barbican.SimpleCryptoKEKGeneratedAnnotation: metav1.Now().UTC().Format(time.RFC3339),
+				},
+			},
+			Data: map[string][]byte{
+				instance.Spec.PasswordSelectors.SimpleCryptoKEK: []byte(kek),
+			},
+		}
+		// the owner reference is not a controller reference and is removed
+		// in reconcileDelete, so the Secret is retained when the CR is deleted
+		err = controllerutil.SetOwnerReference(instance, kekSecret, r.Scheme)
+		if err != nil {
+			return err
+		}
+		err = r.Create(ctx, kekSecret)
+		if err != nil {
+			return err
+		}
+		Log.Info(fmt.Sprintf("Generated Simple Crypto KEK secret %s", kekSecret.Name))
+	}
+
+	generated, ok := kekSecret.Annotations[barbican.SimpleCryptoKEKGeneratedAnnotation]
+	if !ok {
+		instance.Status.SimpleCryptoKEKGenerated = false
+		instance.Status.SimpleCryptoKEKGenerationTime = nil
+		return nil
+	}
+
+	instance.Status.SimpleCryptoKEKGenerated = true
+	generationTime, err := time.Parse(time.RFC3339, generated)
+	if err != nil {
+		Log.Info(fmt.Sprintf("Invalid %s annotation on secret %s: %s",
+			barbican.SimpleCryptoKEKGeneratedAnnotation, kekSecret.Name, err))
+		instance.Status.SimpleCryptoKEKGenerationTime = nil
+		return nil
+	}
+	instance.Status.SimpleCryptoKEKGenerationTime = &metav1.Time{Time: generationTime}
+
+	return nil
+}
+
+// retainSimpleCryptoKEK removes the owner reference from a SimpleCryptoBackendSecret
+// generated by the operator so that it is not garbage collected with the CR
+func (r *BarbicanReconciler) retainSimpleCryptoKEK(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+) error {
+	kekSecret, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.SimpleCryptoBackendSecret, instance.Namespace)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if _, ok := kekSecret.Annotations[barbican.SimpleCryptoKEKGeneratedAnnotation]; !ok {
+		return nil
+	}
+
+	if !slices.ContainsFunc(kekSecret.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+		return ref.UID == instance.GetUID()
+	}) {
+		return nil
+	}
+
+	err = controllerutil.RemoveOwnerReference(instance, kekSecret, r.Scheme)
+	if err != nil {
+		return err
+	}
+	err = r.Update(ctx, kekSecret)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return err
+	}
+	util.LogForObject(h, fmt.Sprintf("Retained generated Simple Crypto KEK secret %s", kekSecret.Name), instance)
+
+	return nil
+}
+
+func (r *BarbicanReconciler) verifySecret(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+	secretName string,
+	expectedFields []string,
+	envVars *map[string]env.Setter,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	// All expectedFields are password fields, so we associate each with a
+	// password validator to ensure invalid detected patterns are rejected.
+	validateFields := map[string]oko_secret.Validator{}
+	for _, f := range expectedFields {
+		validateFields[f] = oko_secret.PasswordValidator{}
+	}
+	hash, result, err := oko_secret.VerifySecretFields(
+		ctx,
+		types.NamespacedName{Name: secretName, Namespace: instance.Namespace},
+		validateFields,
+		h.GetClient(),
+		time.Second*10)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
 			condition.InputReadyErrorMessage,
 			err.Error()))
 		return ctrl.Result{}, err
@@ -1359,6 +2278,52 @@ func (r *BarbicanReconciler) verifySecret(
 	return ctrl.Result{}, nil
 }
 
+// verifyVaultSecrets checks the Secrets referenced by the Vault secret store
+// and reports the result in the BarbicanVaultInputReady condition
+func (r *BarbicanReconciler) verifyVaultSecrets(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+	envVars *map[string]env.Setter,
+) (ctrl.Result, error) {
+	type vaultSecret struct {
+		name   string
+		fields []string
+	}
+	secrets := []vaultSecret{
+		{name: instance.Spec.Vault.AuthSecret, fields: barbican.GetVaultAuthSecretKeys(instance.Spec.Vault.AuthMethod)},
+	}
+	if instance.Spec.Vault.CASecret != "" {
+		secrets = append(secrets, vaultSecret{name: instance.Spec.Vault.CASecret, fields: []string{barbican.VaultCAKey}})
+	}
+
+	for _, s := range secrets {
+		ctrlResult, err := r.verifySecret(ctx, h, instance, s.name, s.fields, envVars)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				barbicanv1beta1.BarbicanVaultInputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				barbicanv1beta1.BarbicanVaultInputReadyErrorMessage,
+				err.Error()))
+			return ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				barbicanv1beta1.BarbicanVaultInputReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				barbicanv1beta1.BarbicanVaultInputReadyWaitingMessage,
+				s.name))
+			return ctrlResult, nil
+		}
+	}
+
+	instance.Status.Conditions.MarkTrue(
+		barbicanv1beta1.BarbicanVaultInputReadyCondition,
+		barbicanv1beta1.BarbicanVaultInputReadyMessage)
+	return ctrl.Result{}, nil
+}
+
 func (r *BarbicanReconciler) ensureDB(
 	ctx context.Context,
 	h *helper.Helper,
@@ -1443,6 +2408,48 @@ func (r *BarbicanReconciler) ensureDB(
 	return db, ctrlResult, nil
 }
 
+// ensureExternalDB records the host of the externally managed DB. The DB and
+// its user are not created by the operator, the db-sync fails while they do
+// not exist.
+func (r *BarbicanReconciler) ensureExternalDB(
+	instance *barbicanv1beta1.Barbican,
+) error {
+	if instance.Spec.ExternalDatabase == nil {
+		err := fmt.Errorf("%w: %s", ErrExternalDatabaseMissing, instance.Spec.DatabaseType)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DBReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DBReadyErrorMessage,
+			err.Error()))
+		return err
+	}
+
+	// update Status.DatabaseHostname, used to config the service
+	instance.Status.DatabaseHostname = instance.Spec.ExternalDatabase.Host
+	instance.Status.Conditions.MarkTrue(condition.DBReadyCondition, condition.DBReadyMessage)
+	return nil
+}
+
+// getExternalDatabaseConnection returns the connection to the externally
+// managed DB with the credentials of its Secret
+func (r *BarbicanReconciler) getExternalDatabaseConnection(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+) (string, error) {
+	credentials, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.ExternalDatabase.Secret, instance.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return barbican.GetPostgreSQLDatabaseConnection(
+		instance,
+		string(credentials.Data[barbicanv1beta1.ExternalDatabaseUsernameSelector]),
+		string(credentials.Data[barbicanv1beta1.ExternalDatabasePasswordSelector]),
+	), nil
+}
+
 func cleanupOldDeployment(
 	ctx context.Context,
 	c client.Client,
@@ -1470,3 +2477,1023 @@ func cleanupOldDeployment(
 
 	return nil
 }
+
+// ensureSoftHSMLoginSecret creates the LoginSecret with random PINs for the
+// softhsm token when it does not exist yet
+func (r *BarbicanReconciler) ensureSoftHSMLoginSecret(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+	serviceLabels map[string]string,
+) error {
+	Log := r.GetLogger(ctx)
+
+	_, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.PKCS11.LoginSecret, instance.Namespace)
+	if err == nil || !k8s_errors.IsNotFound(err) {
+		// verifySecret reports a LoginSecret without the expected fields
+		return err
+	}
+
+	pin, err := barbican.GenerateSoftHSMPin()
+	if err != nil {
+		return fmt.Errorf("error generating SoftHSM PIN: %w", err)
+	}
+	soPin, err := barbican.GenerateSoftHSMPin()
+	if err != nil {
+		return fmt.Errorf("error generating SoftHSM SO PIN: %w", err)
+	}
+
+	loginSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Spec.PKCS11.LoginSecret,
+			Namespace: instance.Namespace,
+			Labels:    serviceLabels,
+		},
+		Data: map[string][]byte{
+			instance.Spec.PasswordSelectors.PKCS11Pin: []byte(pin),
+			barbican.SoftHSMSOPinKey:                  []byte(soPin),
+		},
+	}
+	// the PINs are useless without the token, so the Secret goes away with it
+	err = controllerutil.SetControllerReference(instance, loginSecret, r.Scheme)
+	if err != nil {
+		return err
+	}
+	err = r.Create(ctx, loginSecret)
+	if err != nil {
+		return err
+	}
+	Log.Info(fmt.Sprintf("Generated SoftHSM login secret %s", loginSecret.Name))
+
+	return nil
+}
+
+// reconcileSoftHSMToken creates the PVC holding the softhsm token and runs
+// the job initialising the token in it
+func (r *BarbicanReconciler) reconcileSoftHSMToken(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	h *helper.Helper,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	pvcDef, err := barbican.SoftHSMTokenPVC(instance, serviceLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcDef.Name,
+			Namespace: pvcDef.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, pvc, func() error {
+		pvc.Labels = util.MergeStringMaps(pvc.Labels, pvcDef.Labels)
+		// the claim spec is immutable once bound, only set it on create
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec = pvcDef.Spec
+		}
+		return controllerutil.SetControllerReference(instance, pvc, r.Scheme)
+	})
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			PKCS11PrepReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			SoftHSMInitErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		Log.Info(fmt.Sprintf("PersistentVolumeClaim %s successfully reconciled - operation: %s", pvc.Name, string(op)))
+	}
+
+	initHash := instance.Status.Hash[barbicanv1beta1.SoftHSMInitHash]
+	jobDef := barbican.SoftHSMInitJob(instance, serviceLabels, serviceAnnotations)
+	initJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.SoftHSMInitHash,
+		instance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		initHash,
+	)
+	ctrlResult, err := initJob.DoJob(ctx, h)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			PKCS11PrepReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			SoftHSMInitRunningMessage))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			PKCS11PrepReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			SoftHSMInitErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if initJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.SoftHSMInitHash] = initJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.SoftHSMInitHash]))
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// isConfigRolledOut returns whether the pods of the BarbicanAPI and the
+// BarbicanWorker run with the current config-data Secret of the Barbican.
+// Their Ready conditions are not enough, they stay True until the services
+// reconcile the new config.
+func (r *BarbicanReconciler) isConfigRolledOut(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *barbicanv1beta1.Barbican,
+) (bool, error) {
+	_, configHash, err := oko_secret.GetSecret(ctx, h, fmt.Sprintf("%s-config-data", instance.Name), instance.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	api := &barbicanv1beta1.BarbicanAPI{}
+	worker := &barbicanv1beta1.BarbicanWorker{}
+	services := map[string]client.Object{
+		"api":    api,
+		"worker": worker,
+	}
+	for suffix, obj := range services {
+		err = r.Client.Get(ctx, types.NamespacedName{
+			Name:      fmt.Sprintf("%s-%s", instance.Name, suffix),
+			Namespace: instance.Namespace,
+		}, obj)
+		if k8s_errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+	}
+	return api.Status.Hash[barbicanv1beta1.ConfigRolledOutHash] == configHash &&
+		worker.Status.Hash[barbicanv1beta1.ConfigRolledOutHash] == configHash, nil
+}
+
+// getGlobalDefaultSecretStore returns the secret store to render as the global
+// default, which is the source store while a secret store migration is not
+// completed
+func getGlobalDefaultSecretStore(instance *barbicanv1beta1.Barbican) barbicanv1beta1.SecretStore {
+	migration := instance.Spec.SecretStoreMigration
+	if migration == nil {
+		return instance.Spec.GlobalDefaultSecretStore
+	}
+	status := instance.Status.SecretStoreMigration
+	if status == nil || status.Source != migration.Source || status.Target != migration.Target ||
+		status.State != barbicanv1beta1.SecretStoreMigrationCompleted {
+		return migration.Source
+	}
+	return instance.Spec.GlobalDefaultSecretStore
+}
+
+func (r *BarbicanReconciler) reconcileSecretStoreMigration(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	migration := instance.Spec.SecretStoreMigration
+	if migration == nil {
+		instance.Status.Conditions.MarkTrue(SecretStoreMigrationReadyCondition, SecretStoreMigrationReadyNotRequestedMessage)
+		return ctrl.Result{}, nil
+	}
+
+	status := instance.Status.SecretStoreMigration
+	if status == nil || status.Source != migration.Source || status.Target != migration.Target {
+		now := metav1.Now()
+		status = &barbicanv1beta1.SecretStoreMigrationStatus{
+			Source:    migration.Source,
+			Target:    migration.Target,
+			State:     barbicanv1beta1.SecretStoreMigrationPending,
+			StartTime: &now,
+		}
+		instance.Status.SecretStoreMigration = status
+	}
+
+	if status.State == barbicanv1beta1.SecretStoreMigrationCompleted {
+		instance.Status.Conditions.MarkTrue(SecretStoreMigrationReadyCondition,
+			SecretStoreMigrationReadyMessage, status.Source, status.Target)
+		return ctrl.Result{}, nil
+	}
+
+	// a failed migration is retried once its Job was deleted, the new start
+	// time changes the job hash. The secrets already migrated are not in the
+	// source store anymore.
+	if status.State == barbicanv1beta1.SecretStoreMigrationFailed {
+		err := r.Get(ctx, types.NamespacedName{Name: barbican.GetSecretStoreMigrationJobName(instance.Name), Namespace: instance.Namespace}, &batchv1.Job{})
+		if k8s_errors.IsNotFound(err) {
+			Log.Info(fmt.Sprintf("Service '%s' - retrying the failed secret store migration from %s to %s", instance.Name, status.Source, status.Target))
+			now := metav1.Now()
+			status.StartTime = &now
+			status.State = barbicanv1beta1.SecretStoreMigrationPending
+			status.Secrets, status.MigratedSecrets, status.FailedSecrets = 0, 0, 0
+		} else if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// the services need to run with the config enabling both stores first
+	rolledOut, err := r.isConfigRolledOut(ctx, helper, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !rolledOut {
+		Log.Info(fmt.Sprintf("Service '%s' - waiting for API and Worker to roll out the config before migrating the secrets", instance.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			SecretStoreMigrationReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			SecretStoreMigrationReadyPendingMessage,
+			status.Source, status.Target))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+	status.State = barbicanv1beta1.SecretStoreMigrationRunning
+
+	migrationHash := instance.Status.Hash[barbicanv1beta1.SecretStoreMigrationHash]
+	jobDef := barbican.SecretStoreMigrationJob(instance, serviceLabels, serviceAnnotations, status)
+
+	// the progress of the migration is read before DoJob deletes the
+	// finished Job
+	migrationMessage, err := getJobTerminationMessage(ctx, r.Client, jobDef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if migrationMessage != "" {
+		status.Secrets, status.MigratedSecrets, status.FailedSecrets = barbican.ParseSecretStoreMigrationMessage(migrationMessage)
+	}
+
+	migrationJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.SecretStoreMigrationHash,
+		instance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		migrationHash,
+	)
+	ctrlResult, err := migrationJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			SecretStoreMigrationReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			SecretStoreMigrationReadyRunningMessage,
+			status.Source, status.Target))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		status.State = barbicanv1beta1.SecretStoreMigrationFailed
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			SecretStoreMigrationReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			SecretStoreMigrationReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if migrationJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.SecretStoreMigrationHash] = migrationJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.SecretStoreMigrationHash]))
+	}
+
+	status.State = barbicanv1beta1.SecretStoreMigrationCompleted
+	now := metav1.Now()
+	status.CompletionTime = &now
+	instance.Status.Conditions.MarkTrue(SecretStoreMigrationReadyCondition,
+		SecretStoreMigrationReadyMessage, status.Source, status.Target)
+	Log.Info(fmt.Sprintf("Service '%s' - secrets migrated from %s to %s", instance.Name, status.Source, status.Target))
+
+	// requeue to render the target store as the global default
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// getPendingRestore returns the oldest BarbicanRestore of the Barbican which
+// did not complete, nil when there is none. A failed restore stays pending
+// until it is deleted, so the services never start on a partial restore. The
+// bundles hold a mysqldump, the restores of a Barbican using another database
+// are rejected and never complete, they are ignored.
+func (r *BarbicanReconciler) getPendingRestore(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+) (*barbicanv1beta1.BarbicanRestore, error) {
+	if instance.Spec.DatabaseType == barbicanv1beta1.DatabaseTypePostgreSQL {
+		return nil, nil
+	}
+
+	restores := &barbicanv1beta1.BarbicanRestoreList{}
+	err := r.List(ctx, restores, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending *barbicanv1beta1.BarbicanRestore
+	for i, restore := range restores.Items {
+		if restore.Spec.BarbicanName != instance.Name || !restore.DeletionTimestamp.IsZero() || restore.IsReady() {
+			continue
+		}
+		if pending == nil || restore.CreationTimestamp.Before(&pending.CreationTimestamp) {
+			pending = &restores.Items[i]
+		}
+	}
+	return pending, nil
+}
+
+// reconcileDBBackup dumps the database before the db-sync runs for a new API
+// image, the database schema status records the image of the last db-sync.
+// The db-sync waits for the dump, and a failed dump blocks it until the
+// backup is disabled, or its Job is deleted: the dump is then retried under a
+// new name.
+func (r *BarbicanReconciler) reconcileDBBackup(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if instance.Spec.DBBackup == nil {
+		instance.Status.Conditions.MarkTrue(DBBackupReadyCondition, DBBackupReadyNotRequestedMessage)
+		return ctrl.Result{}, nil
+	}
+
+	image := instance.Spec.BarbicanAPI.ContainerImage
+	status := instance.Status.DBBackup
+	schema := instance.Status.DatabaseSchema
+	if schema == nil || schema.Image == image ||
+		(status != nil && status.Image == image && status.State == barbicanv1beta1.BarbicanDBBackupCompleted) {
+		if status != nil && status.State == barbicanv1beta1.BarbicanDBBackupCompleted {
+			instance.Status.Conditions.MarkTrue(DBBackupReadyCondition, DBBackupReadyMessage, status.Dump)
+		} else {
+			instance.Status.Conditions.MarkTrue(DBBackupReadyCondition, DBBackupReadyNotNeededMessage)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// a failed dump is retried under a new name once its Job was deleted
+	if status != nil && status.State == barbicanv1beta1.BarbicanDBBackupFailed {
+		err := r.Get(ctx, types.NamespacedName{Name: barbican.GetDBBackupJobName(instance.Name), Namespace: instance.Namespace}, &batchv1.Job{})
+		if k8s_errors.IsNotFound(err) {
+			Log.Info(fmt.Sprintf("Service '%s' - retrying the failed database dump %s", instance.Name, status.Dump))
+			status = nil
+		} else if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// the name of the dump is set once, so the job stays the same until the
+	// dump completes
+	if status == nil || status.Image != image {
+		now := metav1.Now()
+		status = &barbicanv1beta1.BarbicanDBBackupStatus{
+			Dump:      barbican.GetDBBackupDumpName(instance.Name, now.Time),
+			Image:     image,
+			StartTime: &now,
+		}
+		instance.Status.DBBackup = status
+	}
+	status.State = barbicanv1beta1.BarbicanDBBackupRunning
+
+	databaseAccount, _, err := mariadbv1.GetAccountAndSecret(
+		ctx, helper, instance.Spec.DatabaseAccount, instance.Namespace)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBBackupReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			DBBackupReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	backupHash := instance.Status.Hash[barbicanv1beta1.DBBackupHash]
+	jobDef := barbican.DBBackupJob(instance, serviceLabels, serviceAnnotations, status, databaseAccount)
+
+	backupJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.DBBackupHash,
+		instance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		backupHash,
+	)
+	ctrlResult, err := backupJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBBackupReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			DBBackupReadyRunningMessage,
+			status.Dump))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		status.State = barbicanv1beta1.BarbicanDBBackupFailed
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBBackupReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			DBBackupReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if backupJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.DBBackupHash] = backupJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.DBBackupHash]))
+	}
+
+	status.State = barbicanv1beta1.BarbicanDBBackupCompleted
+	now := metav1.Now()
+	status.CompletionTime = &now
+	instance.Status.Conditions.MarkTrue(DBBackupReadyCondition, DBBackupReadyMessage, status.Dump)
+	Log.Info(fmt.Sprintf("Service '%s' - database dumped to %s", instance.Name, status.Dump))
+
+	return ctrl.Result{}, nil
+}
+
+// setDBSchemaCondition raises the DBSchemaReady condition from the moment the
+// API image differs from the image of the last db-sync, until the db-sync of
+// the new image recorded its revision. The head is reported once the db-sync
+// of the new image started.
+func setDBSchemaCondition(instance *barbicanv1beta1.Barbican, head string, failed bool) {
+	schema := instance.Status.DatabaseSchema
+	image := instance.Spec.BarbicanAPI.ContainerImage
+	var reason condition.Reason = condition.RequestedReason
+	severity := condition.SeverityInfo
+	if failed {
+		reason, severity = condition.ErrorReason, condition.SeverityWarning
+	}
+	switch {
+	case schema == nil:
+		instance.Status.Conditions.MarkTrue(DBSchemaReadyCondition, DBSchemaReadyNotReportedMessage)
+	case head != "" && head != schema.Revision:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBSchemaReadyCondition,
+			reason,
+			severity,
+			DBSchemaReadyNewerHeadMessage,
+			image, head, schema.Revision))
+	case schema.Image != image:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBSchemaReadyCondition,
+			reason,
+			severity,
+			DBSchemaReadyNotSyncedMessage,
+			image, schema.Revision, schema.Image))
+	case schema.Revision == "":
+		instance.Status.Conditions.MarkTrue(DBSchemaReadyCondition, DBSchemaReadyNotReportedMessage)
+	default:
+		instance.Status.Conditions.MarkTrue(DBSchemaReadyCondition, DBSchemaReadyMessage, schema.Revision)
+	}
+}
+
+// reconcileDBPurge owns the CronJob cleaning the database when DBPurge is set,
+// and mirrors the result of its last finished Job in the DBPurgeReady condition,
+// which is not part of the Ready condition
+func (r *BarbicanReconciler) reconcileDBPurge(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	if instance.Spec.DBPurge == nil {
+		cronJobDef := &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Name + "-db-purge",
+				Namespace: instance.Namespace,
+			},
+		}
+		err := cronjob.NewCronJob(cronJobDef, time.Duration(5)*time.Second).Delete(ctx, helper)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		instance.Status.Conditions.MarkTrue(DBPurgeReadyCondition, DBPurgeReadyNotRequestedMessage)
+		return ctrl.Result{}, nil
+	}
+
+	cronJobDef := barbican.DBPurgeCronJob(instance, serviceLabels, serviceAnnotations)
+	ctrlResult, err := cronjob.NewCronJob(cronJobDef, time.Duration(5)*time.Second).CreateOrPatch(ctx, helper)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBPurgeReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			DBPurgeReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	lastJob, err := r.getDBPurgeLastJob(ctx, instance, helper, serviceLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if lastJob == nil {
+		instance.Status.Conditions.MarkTrue(DBPurgeReadyCondition, DBPurgeReadyScheduledMessage)
+		return ctrl.Result{}, nil
+	}
+	if lastJob.Status.Succeeded == 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			DBPurgeReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			DBPurgeReadyErrorMessage,
+			fmt.Sprintf("job %s failed", lastJob.Name)))
+		return ctrl.Result{}, nil
+	}
+	instance.Status.Conditions.MarkTrue(DBPurgeReadyCondition, DBPurgeReadyMessage, lastJob.Name)
+	return ctrl.Result{}, nil
+}
+
+// readyConditions returns the conditions the Ready condition is computed from.
+// A failed db purge run does not affect the services, it is only reported in
+// the DBPurgeReady condition.
+func readyConditions(instance *barbicanv1beta1.Barbican) condition.Conditions {
+	conditions := instance.Status.Conditions.DeepCopy()
+	conditions.Remove(DBPurgeReadyCondition)
+	return conditions
+}
+
+// getDBPurgeLastJob returns the most recent finished Job of the db purge
+// CronJob, nil until a Job finished
+func (r *BarbicanReconciler) getDBPurgeLastJob(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+) (*batchv1.Job, error) {
+	cronJob, err := cronjob.GetCronJobWithName(ctx, helper, instance.Name+"-db-purge", instance.Namespace)
+	if k8s_errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	jobs := &batchv1.JobList{}
+	err = r.Client.List(ctx, jobs, client.InNamespace(instance.Namespace), client.MatchingLabels(serviceLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	var lastJob *batchv1.Job
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !metav1.IsControlledBy(job, cronJob) || !isJobFinished(job) {
+			continue
+		}
+		if lastJob == nil || lastJob.CreationTimestamp.Before(&job.CreationTimestamp) {
+			lastJob = job
+		}
+	}
+	return lastJob, nil
+}
+
+// isJobFinished returns whether the Job succeeded or failed, the same way
+// the db-sync and the other Jobs of the operator are checked
+func isJobFinished(job *batchv1.Job) bool {
+	return job.Status.Active == 0 && (job.Status.Succeeded > 0 || job.Status.Failed > 0)
+}
+
+// getDeployedImages returns the container images of the BarbicanAPI,
+// BarbicanWorker and BarbicanKeystoneListener, nil until all of them exist
+func (r *BarbicanReconciler) getDeployedImages(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+) (*barbicanv1beta1.BarbicanUpdateImages, error) {
+	api := &barbicanv1beta1.BarbicanAPI{}
+	worker := &barbicanv1beta1.BarbicanWorker{}
+	keystoneListener := &barbicanv1beta1.BarbicanKeystoneListener{}
+	for suffix, obj := range map[string]client.Object{
+		"api":               api,
+		"worker":            worker,
+		"keystone-listener": keystoneListener,
+	} {
+		err := r.Client.Get(ctx, types.NamespacedName{
+			Name:      fmt.Sprintf("%s-%s", instance.Name, suffix),
+			Namespace: instance.Namespace,
+		}, obj)
+		if k8s_errors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return &barbicanv1beta1.BarbicanUpdateImages{
+		API:              api.Spec.ContainerImage,
+		Worker:           worker.Spec.ContainerImage,
+		KeystoneListener: keystoneListener.Spec.ContainerImage,
+	}, nil
+}
+
+// startUpdate starts a minor update when the container images of the spec
+// differ from the ones of the services. The initial deployment is not an
+// update, and a change of the images during an update starts it over.
+func (r *BarbicanReconciler) startUpdate(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+) error {
+	Log := r.GetLogger(ctx)
+
+	target := barbican.GetTargetImages(instance)
+	update := instance.Status.Update
+	if update == nil || update.Phase == barbicanv1beta1.BarbicanUpdateCompleted || update.TargetImages != target {
+		deployed, err := r.getDeployedImages(ctx, instance)
+		if err != nil {
+			return err
+		}
+		if deployed != nil && *deployed != target {
+			now := metav1.Now()
+			update = &barbicanv1beta1.BarbicanUpdateStatus{
+				Phase:          barbicanv1beta1.BarbicanUpdateDBSync,
+				TargetImages:   target,
+				PreviousImages: *deployed,
+				StartTime:      &now,
+			}
+			instance.Status.Update = update
+			Log.Info(fmt.Sprintf("Service '%s' - minor update started", instance.Name))
+		}
+	}
+
+	if update != nil && update.Phase == barbicanv1beta1.BarbicanUpdateDBSync {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			MinorUpdateReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			MinorUpdateReadyRunningMessage,
+			update.Phase))
+	}
+	return nil
+}
+
+// getUpdateProgress returns whether the service rolled out the target image,
+// and the failure of its rollout when it stalled
+func getUpdateProgress(
+	kind string,
+	obj client.Object,
+	image string,
+	target string,
+	observedGeneration int64,
+	conditions condition.Conditions,
+) (bool, string) {
+	if image != target || observedGeneration != obj.GetGeneration() {
+		return false, ""
+	}
+	if conditions.IsTrue(condition.ReadyCondition) {
+		return true, ""
+	}
+	c := conditions.Get(barbicanv1beta1.BarbicanRolloutReadyCondition)
+	if c != nil && c.Status == corev1.ConditionFalse && c.Severity == condition.SeverityError {
+		return false, fmt.Sprintf("%s %s: %s", kind, obj.GetName(), c.Message)
+	}
+	return false, ""
+}
+
+// getUpdatePhaseProgress returns whether the services of the current phase of
+// the minor update rolled out the target images, and the failure of their
+// rollout if any
+func (r *BarbicanReconciler) getUpdatePhaseProgress(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+) (bool, string, error) {
+	update := instance.Status.Update
+	if update.Phase == barbicanv1beta1.BarbicanUpdateAPI {
+		api := &barbicanv1beta1.BarbicanAPI{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-api", instance.Name), Namespace: instance.Namespace}, api)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return false, "", err
+		}
+		done, failure := getUpdateProgress("BarbicanAPI", api, api.Spec.ContainerImage,
+			update.TargetImages.API, api.Status.ObservedGeneration, api.Status.Conditions)
+		return done, failure, nil
+	}
+
+	worker := &barbicanv1beta1.BarbicanWorker{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-worker", instance.Name), Namespace: instance.Namespace}, worker)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return false, "", err
+	}
+	workerDone, workerFailure := getUpdateProgress("BarbicanWorker", worker, worker.Spec.ContainerImage,
+		update.TargetImages.Worker, worker.Status.ObservedGeneration, worker.Status.Conditions)
+	if workerFailure != "" {
+		return false, workerFailure, nil
+	}
+
+	keystoneListener := &barbicanv1beta1.BarbicanKeystoneListener{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-keystone-listener", instance.Name), Namespace: instance.Namespace}, keystoneListener)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return false, "", err
+	}
+	keystoneListenerDone, keystoneListenerFailure := getUpdateProgress("BarbicanKeystoneListener", keystoneListener,
+		keystoneListener.Spec.ContainerImage, update.TargetImages.KeystoneListener,
+		keystoneListener.Status.ObservedGeneration, keystoneListener.Status.Conditions)
+	return workerDone && keystoneListenerDone, keystoneListenerFailure, nil
+}
+
+// reconcileUpdate moves the minor update through its phases once the services
+// of the current phase rolled out the target images. A phase whose services
+// fail to roll out halts the update with HaltUpdateOnFailure, otherwise the
+// failure is recorded and the update moves on. The db-sync Job of reconcileInit
+// must succeed before this is reached, so a failed db-sync always halts it.
+func (r *BarbicanReconciler) reconcileUpdate(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+) error {
+	Log := r.GetLogger(ctx)
+
+	update := instance.Status.Update
+	if update == nil {
+		instance.Status.Conditions.MarkTrue(MinorUpdateReadyCondition, MinorUpdateReadyNotRequestedMessage)
+		return nil
+	}
+
+	if update.Phase == barbicanv1beta1.BarbicanUpdateDBSync {
+		update.Phase = barbicanv1beta1.BarbicanUpdateKeystoneListenerAndWorker
+		Log.Info(fmt.Sprintf("Service '%s' - minor update moved to phase %s", instance.Name, update.Phase))
+	}
+
+	for update.Phase != barbicanv1beta1.BarbicanUpdateCompleted {
+		done, failure, err := r.getUpdatePhaseProgress(ctx, instance)
+		if err != nil {
+			return err
+		}
+		if failure != "" {
+			update.Message = failure
+			if instance.Spec.HaltUpdateOnFailure {
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					MinorUpdateReadyCondition,
+					condition.ErrorReason,
+					condition.SeverityWarning,
+					MinorUpdateReadyHaltedMessage,
+					update.Phase, failure))
+				return nil
+			}
+			Log.Info(fmt.Sprintf("Service '%s' - minor update phase %s failed, moving on: %s", instance.Name, update.Phase, failure))
+		} else if !done {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				MinorUpdateReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				MinorUpdateReadyRunningMessage,
+				update.Phase))
+			return nil
+		}
+
+		if update.Phase == barbicanv1beta1.BarbicanUpdateKeystoneListenerAndWorker {
+			update.Phase = barbicanv1beta1.BarbicanUpdateAPI
+		} else {
+			update.Phase = barbicanv1beta1.BarbicanUpdateCompleted
+			now := metav1.Now()
+			update.CompletionTime = &now
+		}
+		Log.Info(fmt.Sprintf("Service '%s' - minor update moved to phase %s", instance.Name, update.Phase))
+	}
+
+	instance.Status.Conditions.MarkTrue(MinorUpdateReadyCondition, MinorUpdateReadyMessage)
+	return nil
+}
+
+// reconcileProjectSecretStores makes the projects of ProjectSecretStores prefer
+// the secret store of the spec, and removes the preference of the projects
+// dropped from the spec. Errors are reported in the status, they do not block
+// the reconciliation of the services.
+func (r *BarbicanReconciler) reconcileProjectSecretStores(
+	ctx context.Context,
+	instance *barbicanv1beta1.Barbican,
+	h *helper.Helper,
+	barbicanAPI *barbicanv1beta1.BarbicanAPI,
+) ctrl.Result {
+	Log := r.GetLogger(ctx)
+
+	if len(instance.Spec.ProjectSecretStores) == 0 && len(instance.Status.ProjectSecretStores) == 0 {
+		instance.Status.ProjectSecretStores = nil
+		instance.Status.Conditions.MarkTrue(ProjectSecretStoresReadyCondition, ProjectSecretStoresReadyMessage)
+		return ctrl.Result{}
+	}
+
+	barbicanURL := barbicanAPI.Status.APIEndpoints[string(endpoint.EndpointInternal)]
+	if !instance.Status.Conditions.IsTrue(barbicanv1beta1.BarbicanAPIReadyCondition) || barbicanURL == "" {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ProjectSecretStoresReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ProjectSecretStoresReadyWaitingMessage))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}
+	}
+
+	setError := func(err error) ctrl.Result {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ProjectSecretStoresReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ProjectSecretStoresReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{RequeueAfter: time.Duration(1) * time.Minute}
+	}
+
+	// application credentials are bound to the service project, the
+	// preferences need tokens scoped to the other projects
+	if instance.Spec.Auth.ApplicationCredentialSecret != "" {
+		return setError(ErrProjectSecretStoresAC)
+	}
+
+	keystoneAPI, err := keystonev1.GetKeystoneAPI(ctx, h, instance.Namespace, map[string]string{})
+	if err != nil {
+		return setError(err)
+	}
+	keystoneURL, err := keystoneAPI.GetEndpoint(endpoint.EndpointInternal)
+	if err != nil {
+		return setError(err)
+	}
+	ospSecret, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.Secret, instance.Namespace)
+	if err != nil {
+		return setError(err)
+	}
+
+	// the internal endpoints are served with certificates of the CA bundle
+	transport := r.SecretStoreClientTransport
+	if transport == nil && instance.Spec.BarbicanAPI.TLS.CaBundleSecretName != "" {
+		caBundleSecret, _, err := oko_secret.GetSecret(ctx, h, instance.Spec.BarbicanAPI.TLS.CaBundleSecretName, instance.Namespace)
+		if err != nil {
+			return setError(err)
+		}
+		transport, err = barbican.NewSecretStoreTransport(caBundleSecret.Data[tls.CABundleKey])
+		if err != nil {
+			return setError(err)
+		}
+	}
+
+	storeClient := barbican.NewSecretStoreClient(
+		keystoneURL,
+		barbicanURL,
+		instance.Spec.ServiceUser,
+		string(ospSecret.Data[instance.Spec.PasswordSelectors.Service]),
+		transport,
+	)
+
+	if instance.Status.ProjectSecretStores == nil {
+		instance.Status.ProjectSecretStores = map[string]barbicanv1beta1.ProjectSecretStoreStatus{}
+	}
+
+	// the API calls are bounded so that slow or unreachable APIs do not hold
+	// the reconciliation, the projects left are handled after a requeue
+	storeCtx, cancel := context.WithTimeout(ctx, ProjectSecretStoresTimeout)
+	defer cancel()
+	pending := false
+
+	failed := []string{}
+	for _, project := range slices.Sorted(maps0.Keys(instance.Spec.ProjectSecretStores)) {
+		if storeCtx.Err() != nil {
+			pending = true
+			break
+		}
+		status := instance.Status.ProjectSecretStores[project]
+		err := r.reconcileProjectSecretStore(storeCtx, storeClient, project, instance.Spec.ProjectSecretStores[project], &status)
+		if err != nil && storeCtx.Err() != nil {
+			pending = true
+			break
+		}
+		if err != nil {
+			Log.Error(err, fmt.Sprintf("Failed to reconcile the preferred secret store of project %s", project))
+			status.Error = err.Error()
+			failed = append(failed, project)
+		} else {
+			status.Error = ""
+		}
+		now := metav1.Now()
+		status.LastSyncTime = &now
+		instance.Status.ProjectSecretStores[project] = status
+	}
+
+	// the projects dropped from the spec go back to the global default
+	for _, project := range slices.Sorted(maps0.Keys(instance.Status.ProjectSecretStores)) {
+		if _, ok := instance.Spec.ProjectSecretStores[project]; ok {
+			continue
+		}
+		if pending || storeCtx.Err() != nil {
+			pending = true
+			break
+		}
+		status := instance.Status.ProjectSecretStores[project]
+		err := r.reconcileProjectSecretStore(storeCtx, storeClient, project, "", &status)
+		if err != nil && storeCtx.Err() != nil {
+			pending = true
+			break
+		}
+		if err != nil {
+			Log.Error(err, fmt.Sprintf("Failed to remove the preferred secret store of project %s", project))
+			status.Error = err.Error()
+			instance.Status.ProjectSecretStores[project] = status
+			failed = append(failed, project)
+			continue
+		}
+		delete(instance.Status.ProjectSecretStores, project)
+	}
+
+	if len(failed) != 0 {
+		return setError(fmt.Errorf("%w: projects %s", barbican.ErrSecretStoreAPI, strings.Join(failed, ", ")))
+	}
+	if pending {
+		Log.Info("Project secret stores left for the next reconciliation")
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ProjectSecretStoresReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ProjectSecretStoresReadyPendingMessage))
+		return ctrl.Result{RequeueAfter: time.Duration(5) * time.Second}
+	}
+	instance.Status.Conditions.MarkTrue(ProjectSecretStoresReadyCondition, ProjectSecretStoresReadyMessage)
+	if len(instance.Spec.ProjectSecretStores) == 0 {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: ProjectSecretStoresResyncInterval}
+}
+
+// reconcileProjectSecretStore makes the project prefer the desired secret
+// store, or the global default when desired is empty, and records the drift
+// from the desired store in status
+func (r *BarbicanReconciler) reconcileProjectSecretStore(
+	ctx context.Context,
+	storeClient *barbican.SecretStoreClient,
+	project string,
+	desired barbicanv1beta1.SecretStore,
+	status *barbicanv1beta1.ProjectSecretStoreStatus,
+) error {
+	Log := r.GetLogger(ctx)
+
+	token, err := storeClient.ProjectToken(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	preferred, err := storeClient.GetPreferredSecretStore(ctx, token)
+	if err != nil {
+		return err
+	}
+	var current barbicanv1beta1.SecretStore
+	if preferred != nil {
+		var known bool
+		current, known = preferred.SecretStore()
+		if !known {
+			status.Preferred = ""
+			status.InSync = false
+			return fmt.Errorf("%w: project %s prefers secret store %s", barbican.ErrUnknownSecretStore, project, preferred.Name)
+		}
+	}
+	status.Preferred = current
+	if current == desired {
+		status.InSync = true
+		return nil
+	}
+
+	status.InSync = false
+	now := metav1.Now()
+	status.LastDriftTime = &now
+	Log.Info(fmt.Sprintf("Project %s prefers secret store '%s' instead of '%s'", project, current, desired))
+
+	if desired == "" {
+		err = storeClient.UnsetPreferredSecretStore(ctx, token, preferred)
+		if err != nil {
+			return err
+		}
+		status.Preferred = ""
+		status.InSync = true
+		return nil
+	}
+
+	stores, err := storeClient.ListSecretStores(ctx, token)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(stores, func(s barbican.SecretStoreInfo) bool {
+		store, ok := s.SecretStore()
+		return ok && store == desired
+	})
+	if idx < 0 {
+		return fmt.Errorf("%w: secret store %s is not registered in Barbican", barbican.ErrSecretStoreAPI, desired)
+	}
+	err = storeClient.SetPreferredSecretStore(ctx, token, &stores[idx])
+	if err != nil {
+		return err
+	}
+	status.Preferred = desired
+	status.InSync = true
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"path/filepath"
 	"slices"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
 	nad "github.com/openstack-k8s-operators/lib-common/modules/common/networkattachment"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pdb"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
@@ -57,7 +59,9 @@ import (
 
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -97,6 +101,8 @@ func (r *BarbicanAPIReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanapis/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanapis/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=topology.openstack.org,resources=topologies,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile BarbicanAPI
 func (r *BarbicanAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
@@ -167,6 +173,8 @@ func (r *BarbicanAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 		condition.UnknownCondition(condition.ServiceConfigReadyCondition, condition.InitReason, condition.ServiceConfigReadyInitMessage),
 		condition.UnknownCondition(condition.DeploymentReadyCondition, condition.InitReason, condition.DeploymentReadyInitMessage),
+		condition.UnknownCondition(condition.PDBReadyCondition, condition.InitReason, condition.PDBReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanRolloutReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanRolloutReadyInitMessage),
 		// right now we have no dedicated KeystoneServiceReadyInitMessage and KeystoneEndpointReadyInitMessage
 		condition.UnknownCondition(condition.KeystoneEndpointReadyCondition, condition.InitReason, ""),
 		condition.UnknownCondition(condition.NetworkAttachmentsReadyCondition, condition.InitReason, condition.NetworkAttachmentsReadyInitMessage),
@@ -279,13 +287,15 @@ func (r *BarbicanAPIReconciler) createHashOfInputHashes(
 	return hash, changed, nil
 }
 
-// generateServiceConfigs - create Secret which holds the service configuration
+// generateServiceConfigs - create Secret which holds the service configuration,
+// returns the hash of the config-data Secret of the Barbican it is built from
+// and the WSGI tuning rendered in it
 func (r *BarbicanAPIReconciler) generateServiceConfigs(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *barbicanv1beta1.BarbicanAPI,
 	envVars *map[string]env.Setter,
-) error {
+) (string, *barbicanv1beta1.BarbicanAPIWSGIStatus, error) {
 	Log := r.GetLogger(ctx)
 	Log.Info("generateServiceConfigs - reconciling")
 	labels := labels.GetLabels(instance, labels.GetGroupLabel(barbican.ServiceName), map[string]string{})
@@ -299,11 +309,18 @@ func (r *BarbicanAPIReconciler) generateServiceConfigs(
 	// CustomConfigFileName) from the Secret generated by the top level
 	// barbican controller, and add them to this service specific Secret.
 	owner := barbican.GetOwningBarbicanName(instance)
+	barbicanConfigHash := ""
+	var wsgiTuning *barbicanv1beta1.BarbicanAPIWSGIStatus
 	if owner != "" {
 		barbicanSecretName := owner + "-config-data"
-		barbicanSecret, _, err := secret.GetSecret(ctx, h, barbicanSecretName, instance.Namespace)
+		barbicanSecret, hash, err := secret.GetSecret(ctx, h, barbicanSecretName, instance.Namespace)
 		if err != nil {
-			return err
+			return "", nil, err
+		}
+		barbicanConfigHash = hash
+		wsgiTuning, err = barbican.ParseWSGITuning(barbicanSecret.Data[barbican.WSGIConfigFileName])
+		if err != nil {
+			return "", nil, err
 		}
 		customData[barbican.DefaultsConfigFileName] = string(barbicanSecret.Data[barbican.DefaultsConfigFileName])
 		customData[barbican.CustomConfigFileName] = string(barbicanSecret.Data[barbican.CustomConfigFileName])
@@ -320,7 +337,7 @@ func (r *BarbicanAPIReconciler) generateServiceConfigs(
 		ownerInstance := &barbicanv1beta1.Barbican{}
 		err = h.GetClient().Get(ctx, types.NamespacedName{Name: owner, Namespace: instance.Namespace}, ownerInstance)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 		maps.Copy(customData, ownerInstance.Spec.DefaultConfigOverwrite)
 	}
@@ -331,7 +348,7 @@ func (r *BarbicanAPIReconciler) generateServiceConfigs(
 	for _, secretName := range instance.Spec.CustomServiceConfigSecrets {
 		secret, _, err := secret.GetSecret(ctx, h, secretName, instance.Namespace)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 		for _, data := range secret.Data {
 			customSecrets += string(data) + "\n"
@@ -355,11 +372,17 @@ func (r *BarbicanAPIReconciler) generateServiceConfigs(
 		}
 	}
 
+	// the Barbican controller merges the top level logging into the one of
+	// the service, a logging.conf of the defaultConfigOverwrite replaces the
+	// rendered one
+	maps.Copy(templateParameters, barbican.GetLoggingTemplateParameters(instance.Spec.Logging))
+	templateParameters["LogConfigAppend"] = filepath.Join(barbican.ConfigOverwriteDir, barbican.LoggingConfigFileName)
+
 	// To avoid a json parsing error in kolla files, we always need to set PKCS11ClientDataPath
 	// This gets overridden in the PKCS11 section below if needed.
 	templateParameters["PKCS11ClientDataPath"] = barbicanv1beta1.DefaultPKCS11ClientDataPath
 
-	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, labels, false)
+	return barbicanConfigHash, wsgiTuning, GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, serviceAdditionalTemplates, labels, false)
 }
 
 func (r *BarbicanAPIReconciler) reconcileInit(
@@ -585,6 +608,11 @@ func (r *BarbicanAPIReconciler) reconcileDelete(ctx context.Context, instance *b
 		return ctrlResult, err
 	}
 
+	// Remove the PodDisruptionBudget of the service pods
+	if err := pdb.DeletePDBWithName(ctx, helper, instance.Name, instance.Namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Service is deleted so remove the finalizer.
 	controllerutil.RemoveFinalizer(instance, helper.GetFinalizer())
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' delete successfully", instance.Name))
@@ -639,14 +667,53 @@ func (r *BarbicanAPIReconciler) reconcileNormal(ctx context.Context, instance *b
 			return ctrlResult, err
 		}
 
-		// check for PKCS11 secret holding the PKCS11 Client Data
-		Log.Info(fmt.Sprintf("[API] Verify secret '%s'", instance.Spec.PKCS11.ClientDataSecret))
-		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.ClientDataSecret, []string{}, &configVars)
+		// check for PKCS11 secret holding the PKCS11 Client Data, a softhsm
+		// token does not need any
+		if instance.Spec.PKCS11.ClientDataSecret != "" {
+			Log.Info(fmt.Sprintf("[API] Verify secret '%s'", instance.Spec.PKCS11.ClientDataSecret))
+			ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.ClientDataSecret, []string{}, &configVars)
+			if err != nil {
+				return ctrlResult, err
+			}
+		}
+	}
+
+	// check KMIP secrets
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreKMIP) && instance.Spec.KMIP != nil {
+		// check for KMIP secret holding the client certificate and key
+		Log.Info(fmt.Sprintf("[API] Verify secret '%s'", instance.Spec.KMIP.ClientCertSecret))
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.KMIP.ClientCertSecret, []string{barbican.KMIPClientCertKey, barbican.KMIPClientKeyKey}, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+
+		// check for KMIP secret holding the CA certificate
+		Log.Info(fmt.Sprintf("[API] Verify secret '%s'", instance.Spec.KMIP.CASecret))
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.KMIP.CASecret, []string{barbican.KMIPCAKey}, &configVars)
 		if err != nil {
 			return ctrlResult, err
 		}
 	}
 
+	// check Vault secrets
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) && instance.Spec.Vault != nil {
+		// check for Vault secret holding the credentials
+		Log.Info(fmt.Sprintf("[API] Verify secret '%s'", instance.Spec.Vault.AuthSecret))
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.Vault.AuthSecret, barbican.GetVaultAuthSecretKeys(instance.Spec.Vault.AuthMethod), &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+
+		// check for Vault secret holding the CA certificate
+		if instance.Spec.Vault.CASecret != "" {
+			Log.Info(fmt.Sprintf("[API] Verify secret '%s'", instance.Spec.Vault.CASecret))
+			ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.Vault.CASecret, []string{barbican.VaultCAKey}, &configVars)
+			if err != nil {
+				return ctrlResult, err
+			}
+		}
+	}
+
 	// check CustomServiceConfigSecrets
 	for _, v := range instance.Spec.CustomServiceConfigSecrets {
 		Log.Info(fmt.Sprintf("[API] Verify secret '%s' from CustomServiceConfigSecrets", v))
@@ -728,7 +795,7 @@ func (r *BarbicanAPIReconciler) reconcileNormal(ctx context.Context, instance *b
 	//
 	// create custom config for this barbican service
 	//
-	err = r.generateServiceConfigs(ctx, helper, instance, &configVars)
+	barbicanConfigHash, wsgiTuning, err := r.generateServiceConfigs(ctx, helper, instance, &configVars)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -864,13 +931,27 @@ func (r *BarbicanAPIReconciler) reconcileNormal(ctx context.Context, instance *b
 			err.Error()))
 		return ctrl.Result{}, err
 	}
+	// an autoscaled Deployment keeps the replicas set by its
+	// HorizontalPodAutoscaler
+	if instance.Spec.Autoscaling != nil {
+		deplDef.Spec.Replicas, err = getAutoscaledReplicas(ctx, helper, deplDef.Name, deplDef.Namespace, instance.Spec.Autoscaling)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.DeploymentReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.DeploymentReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+	}
 	Log.Info(fmt.Sprintf("[API] Getting deployment '%s'", instance.Name))
 	depl := deployment.NewDeployment(
 		deplDef,
 		time.Duration(5)*time.Second,
 	)
 	Log.Info(fmt.Sprintf("[API] Got deployment '%s'", instance.Name))
-	ctrlResult, err = depl.CreateOrPatch(ctx, helper)
+	ctrlResult, err = barbican.CreateOrPatchDeployment(ctx, helper, depl, deplDef)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -888,7 +969,27 @@ func (r *BarbicanAPIReconciler) reconcileNormal(ctx context.Context, instance *b
 		return ctrlResult, nil
 	}
 
+	err = reconcileHorizontalPodAutoscaler(ctx, helper, deplDef.Name, deplDef.Namespace, serviceLabels, instance.Spec.Autoscaling)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	ctrlResult, err = reconcilePodDisruptionBudget(ctx, helper, &instance.Status.Conditions,
+		deplDef.Name, deplDef.Namespace, serviceLabels, deplDef.Spec.Replicas, instance.Spec.PodDisruptionBudget)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
 	deploy := depl.GetDeployment()
+	setRolloutCondition(&instance.Status.Conditions, deploy)
 	if deploy.Generation == deploy.Status.ObservedGeneration {
 		instance.Status.ReadyCount = deploy.Status.ReadyReplicas
 	}
@@ -919,12 +1020,16 @@ func (r *BarbicanAPIReconciler) reconcileNormal(ctx context.Context, instance *b
 	// Replicas > ReadyReplicas.
 	// In addition, make sure the controller sees the last Generation
 	// by comparing it with the ObservedGeneration.
-	if deployment.IsReady(deploy) {
+	if barbican.IsRolledOut(deploy) {
 		oldDepName := fmt.Sprintf("%s-api", instance.Name)
 		if err := cleanupOldDeployment(ctx, r.Client, instance, oldDepName); err != nil {
 			return ctrl.Result{}, err
 		}
 		instance.Status.Conditions.MarkTrue(condition.DeploymentReadyCondition, condition.DeploymentReadyMessage)
+		// the pods run with the config of the Barbican, the Barbican waits
+		// for it before rewrapping or migrating the secrets
+		instance.Status.Hash[barbicanv1beta1.ConfigRolledOutHash] = barbicanConfigHash
+		instance.Status.WSGI = wsgiTuning
 	} else {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -936,6 +1041,8 @@ func (r *BarbicanAPIReconciler) reconcileNormal(ctx context.Context, instance *b
 
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' in barbicanAPI successfully", instance.Name))
 
+	// Update the lastObserved generation before evaluating conditions
+	instance.Status.ObservedGeneration = instance.Generation
 	// We reached the end of the Reconcile, update the Ready condition based on
 	// the sub conditions
 	if instance.Status.Conditions.AllSubConditionIsTrue() {
@@ -1041,6 +1148,54 @@ func (r *BarbicanAPIReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	// index kmipClientCertSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanAPI{}, kmipClientCertSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanAPI)
+		if cr.Spec.KMIP == nil || cr.Spec.KMIP.ClientCertSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KMIP.ClientCertSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index kmipCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanAPI{}, kmipCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanAPI)
+		if cr.Spec.KMIP == nil || cr.Spec.KMIP.CASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KMIP.CASecret}
+	}); err != nil {
+		return err
+	}
+
+	// index vaultAuthSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanAPI{}, vaultAuthSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanAPI)
+		if cr.Spec.Vault == nil || cr.Spec.Vault.AuthSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Vault.AuthSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index vaultCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanAPI{}, vaultCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanAPI)
+		if cr.Spec.Vault == nil || cr.Spec.Vault.CASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Vault.CASecret}
+	}); err != nil {
+		return err
+	}
+
 	// index topologyField
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanAPI{}, topologyField, func(rawObj client.Object) []string {
 		// Extract the topology name from the spec, if one is provided
@@ -1072,6 +1227,8 @@ func (r *BarbicanAPIReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Secret{}).
 		Owns(&keystonev1.KeystoneEndpoint{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
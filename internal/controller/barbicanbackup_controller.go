@@ -0,0 +1,417 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	oko_secret "github.com/openstack-k8s-operators/lib-common/modules/common/secret"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BarbicanBackupReconciler reconciles a BarbicanBackup object
+type BarbicanBackupReconciler struct {
+	client.Client
+	Kclient kubernetes.Interface
+	Scheme  *runtime.Scheme
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *BarbicanBackupReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("BarbicanBackup")
+}
+
+// +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanbackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanbackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanbackups/finalizers,verbs=update;patch
+
+// Reconcile BarbicanBackup
+func (r *BarbicanBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &barbicanv1beta1.BarbicanBackup{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Object not found
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	// a backup is taken once, a completed bundle is never written again
+	if instance.IsReady() {
+		return ctrl.Result{}, nil
+	}
+	Log.Info(fmt.Sprintf("Reconciling BarbicanBackup %s", instance.Name))
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// initialize status if Conditions is nil, but do not reset if it already
+	// exists
+	isNewInstance := instance.Status.Conditions == nil
+	if isNewInstance {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// Always patch the instance status when exiting this function so we can
+	// persist any changes.
+	defer func() {
+		// Don't update the status, if reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("panic during reconcile %v\n", r))
+			panic(r)
+		}
+		condition.RestoreLastTransitionTimes(
+			&instance.Status.Conditions, savedConditions)
+		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	// Initialize Conditions
+	cl := condition.CreateList(
+		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
+		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanBackupKEKReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanBackupKEKReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanBackupBundleReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanBackupBundleReadyInitMessage),
+	)
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	if isNewInstance {
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+func (r *BarbicanBackupReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *barbicanv1beta1.BarbicanBackup,
+	helper *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	//
+	// check the Barbican and the public key
+	//
+	barbicanInstance := &barbicanv1beta1.Barbican{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Spec.BarbicanName, Namespace: instance.Namespace}, barbicanInstance)
+	if k8s_errors.IsNotFound(err) {
+		Log.Info(fmt.Sprintf("Barbican %s not found", instance.Spec.BarbicanName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			condition.InputReadyWaitingMessage))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the bundle holds a mysqldump
+	if barbicanInstance.Spec.DatabaseType == barbicanv1beta1.DatabaseTypePostgreSQL {
+		err = fmt.Errorf("%w, the databaseType is %s", ErrBackupDatabaseType, barbicanInstance.Spec.DatabaseType)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	publicKey, result, err := getBackupKey(ctx, helper, instance.Namespace,
+		instance.Spec.PublicKeySecret, barbicanv1beta1.BackupPublicKeySelector, &instance.Status.Conditions)
+	if err != nil || (result != ctrl.Result{}) {
+		return result, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	// the name of the bundle is set once, so the job stays the same until
+	// the bundle is complete
+	if instance.Status.Bundle == "" {
+		now := metav1.Now()
+		instance.Status.Bundle = barbican.GetBackupBundleName(instance.Name, now.Time)
+		instance.Status.StartTime = &now
+	}
+
+	//
+	// encrypt the KEK Secret to the public key
+	//
+	err = r.reconcileKEK(ctx, instance, barbicanInstance, publicKey)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanBackupKEKReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanBackupKEKReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	//
+	// write the bundle once the database of the Barbican exists
+	//
+	if barbicanInstance.Status.DatabaseHostname == "" ||
+		!barbicanInstance.Status.Conditions.IsTrue(condition.DBReadyCondition) ||
+		!barbicanInstance.Status.Conditions.IsTrue(condition.ServiceConfigReadyCondition) {
+		Log.Info(fmt.Sprintf("BarbicanBackup %s - waiting for the database of Barbican %s", instance.Name, barbicanInstance.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanBackupBundleReadyWaitingMessage,
+			barbicanInstance.Name))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+
+	databaseAccount, _, err := mariadbv1.GetAccountAndSecret(
+		ctx, helper, barbicanInstance.Spec.DatabaseAccount, instance.Namespace)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanBackupBundleReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	serviceLabels := map[string]string{
+		common.AppSelector: barbican.ServiceName,
+	}
+	bundleHash := instance.Status.Hash[barbicanv1beta1.BackupBundleHash]
+	jobDef := barbican.BackupJob(instance, barbicanInstance, serviceLabels, databaseAccount)
+
+	bundleJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.BackupBundleHash,
+		barbicanInstance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		bundleHash,
+	)
+	ctrlResult, err := bundleJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanBackupBundleReadyRunningMessage,
+			instance.Status.Bundle))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanBackupBundleReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if bundleJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.BackupBundleHash] = bundleJob.GetHash()
+		Log.Info(fmt.Sprintf("BarbicanBackup %s - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.BackupBundleHash]))
+	}
+
+	now := metav1.Now()
+	instance.Status.CompletionTime = &now
+	instance.Status.Conditions.MarkTrue(barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+		barbicanv1beta1.BarbicanBackupBundleReadyMessage, instance.Status.Bundle)
+	Log.Info(fmt.Sprintf("BarbicanBackup %s - bundle %s written", instance.Name, instance.Status.Bundle))
+
+	if instance.Status.Conditions.AllSubConditionIsTrue() {
+		instance.Status.Conditions.MarkTrue(
+			condition.ReadyCondition, condition.ReadyMessage)
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileKEK encrypts the Simple Crypto KEKs of the Barbican to the public
+// key, in the Secret the backup Job copies to the bundle. Only the KEK fields
+// of the SimpleCryptoBackendSecret are encrypted, it is often shared with the
+// other services. They are only encrypted again when a KEK changed, the
+// bundle Job is then run again with the new KEKs.
+func (r *BarbicanBackupReconciler) reconcileKEK(
+	ctx context.Context,
+	instance *barbicanv1beta1.BarbicanBackup,
+	barbicanInstance *barbicanv1beta1.Barbican,
+	publicKey []byte,
+) error {
+	Log := r.GetLogger(ctx)
+
+	if len(barbicanInstance.Spec.EnabledSecretStores) != 0 &&
+		!slices.Contains(barbicanInstance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreSimpleCrypto) {
+		instance.Status.KEKSecret = ""
+		instance.Status.KEKHash = ""
+		instance.Status.Conditions.MarkTrue(barbicanv1beta1.BarbicanBackupKEKReadyCondition,
+			barbicanv1beta1.BarbicanBackupKEKReadyNotNeededMessage)
+		return nil
+	}
+
+	kekSecret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: barbicanInstance.Spec.SimpleCryptoBackendSecret, Namespace: instance.Namespace}, kekSecret)
+	if err != nil {
+		return err
+	}
+	kekData := map[string][]byte{}
+	for _, selector := range getSimpleCryptoKEKSelectors(barbicanInstance) {
+		if value, ok := kekSecret.Data[selector]; ok {
+			kekData[selector] = value
+		}
+	}
+	if len(kekData) == 0 {
+		return fmt.Errorf("%w: %s", ErrBackupKEKMissing, kekSecret.Name)
+	}
+	kekHash, err := util.ObjectHash(kekData)
+	if err != nil {
+		return err
+	}
+
+	encrypted := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      barbican.GetBackupKEKSecretName(instance.Name),
+			Namespace: instance.Namespace,
+		},
+	}
+	err = r.Get(ctx, types.NamespacedName{Name: encrypted.Name, Namespace: encrypted.Namespace}, encrypted)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return err
+	}
+
+	// the encryption is randomized, encrypting the same KEK again would
+	// change the job hash
+	if k8s_errors.IsNotFound(err) || instance.Status.KEKHash != kekHash {
+		data, err := barbican.EncryptKEKSecret(kekData, publicKey)
+		if err != nil {
+			return err
+		}
+		_, err = controllerutil.CreateOrPatch(ctx, r.Client, encrypted, func() error {
+			encrypted.Data = map[string][]byte{
+				barbican.BackupKEKFileName: data,
+			}
+			return controllerutil.SetControllerReference(instance, encrypted, r.Scheme)
+		})
+		if err != nil {
+			return err
+		}
+		Log.Info(fmt.Sprintf("BarbicanBackup %s - KEK Secret %s encrypted", instance.Name, kekSecret.Name))
+	}
+
+	instance.Status.KEKSecret = kekSecret.Name
+	instance.Status.KEKHash = kekHash
+	instance.Status.Conditions.MarkTrue(barbicanv1beta1.BarbicanBackupKEKReadyCondition,
+		barbicanv1beta1.BarbicanBackupKEKReadyMessage, kekSecret.Name)
+	return nil
+}
+
+// getBackupKey returns the PEM encoded key in the field of the Secret, and
+// reports the missing Secret in the InputReady condition
+func getBackupKey(
+	ctx context.Context,
+	h *helper.Helper,
+	namespace string,
+	secretName string,
+	selector string,
+	conditions *condition.Conditions,
+) ([]byte, ctrl.Result, error) {
+	keySecret, _, err := oko_secret.GetSecret(ctx, h, secretName, namespace)
+	if k8s_errors.IsNotFound(err) {
+		h.GetLogger().Info(fmt.Sprintf("Secret %s not found", secretName))
+		conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			condition.InputReadyWaitingMessage))
+		return nil, ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	} else if err != nil {
+		return nil, ctrl.Result{}, err
+	}
+
+	key, ok := keySecret.Data[selector]
+	if !ok {
+		err = fmt.Errorf("%w: %s in Secret %s", ErrBackupKeyMissing, selector, secretName)
+		conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return nil, ctrl.Result{}, err
+	}
+	return key, ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BarbicanBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&barbicanv1beta1.BarbicanBackup{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.Secret{}).
+		Complete(r)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"path/filepath"
 	"slices"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
 	nad "github.com/openstack-k8s-operators/lib-common/modules/common/networkattachment"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pdb"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	"k8s.io/apimachinery/pkg/fields"
@@ -56,6 +58,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -75,6 +78,7 @@ func (r *BarbicanKeystoneListenerReconciler) GetLogger(ctx context.Context) logr
 //+kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanapis/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanapis/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=topology.openstack.org,resources=topologies,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile BarbicanAPI
 func (r *BarbicanKeystoneListenerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
@@ -144,6 +148,8 @@ func (r *BarbicanKeystoneListenerReconciler) Reconcile(ctx context.Context, req
 		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 		condition.UnknownCondition(condition.ServiceConfigReadyCondition, condition.InitReason, condition.ServiceConfigReadyInitMessage),
 		condition.UnknownCondition(condition.DeploymentReadyCondition, condition.InitReason, condition.DeploymentReadyInitMessage),
+		condition.UnknownCondition(condition.PDBReadyCondition, condition.InitReason, condition.PDBReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanRolloutReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanRolloutReadyInitMessage),
 		condition.UnknownCondition(condition.NetworkAttachmentsReadyCondition, condition.InitReason, condition.NetworkAttachmentsReadyInitMessage),
 		condition.UnknownCondition(condition.TLSInputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 	)
@@ -295,7 +301,12 @@ func (r *BarbicanKeystoneListenerReconciler) generateServiceConfigs(
 			}
 		}
 
-		// TODO(alee) Get custom config overwrites from the parent barbican
+		ownerInstance := &barbicanv1beta1.Barbican{}
+		err = h.GetClient().Get(ctx, types.NamespacedName{Name: owner, Namespace: instance.Namespace}, ownerInstance)
+		if err != nil {
+			return err
+		}
+		maps.Copy(customData, ownerInstance.Spec.DefaultConfigOverwrite)
 	}
 
 	maps.Copy(customData, instance.Spec.DefaultConfigOverwrite)
@@ -328,10 +339,16 @@ func (r *BarbicanKeystoneListenerReconciler) generateServiceConfigs(
 		}
 	}
 
+	// the Barbican controller merges the top level logging into the one of
+	// the service, a logging.conf of the defaultConfigOverwrite replaces the
+	// rendered one
+	maps.Copy(templateParameters, barbican.GetLoggingTemplateParameters(instance.Spec.Logging))
+	templateParameters["LogConfigAppend"] = filepath.Join(barbican.ConfigOverwriteDir, barbican.LoggingConfigFileName)
+
 	// To avoid a json parsing error in kolla files, we always need to set PKCS11ClientDataPath
 	templateParameters["PKCS11ClientDataPath"] = barbicanv1beta1.DefaultPKCS11ClientDataPath
 
-	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, labels, false)
+	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, serviceAdditionalTemplates, labels, false)
 }
 
 func (r *BarbicanKeystoneListenerReconciler) reconcileInit(
@@ -398,6 +415,11 @@ func (r *BarbicanKeystoneListenerReconciler) reconcileDelete(ctx context.Context
 	); err != nil {
 		return ctrlResult, err
 	}
+	// Remove the PodDisruptionBudget of the service pods
+	if err := pdb.DeletePDBWithName(ctx, helper, instance.Name, instance.Namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Service is deleted so remove the finalizer.
 	controllerutil.RemoveFinalizer(instance, helper.GetFinalizer())
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' delete successfully", instance.Name))
@@ -620,16 +642,24 @@ func (r *BarbicanKeystoneListenerReconciler) reconcileNormal(ctx context.Context
 		return ctrl.Result{}, fmt.Errorf("waiting for Topology requirements: %w", err)
 	}
 
+	// the readiness probe checks the connection to the RabbitMQ hosts of the
+	// transport URL
+	transportURLSecret, _, err := secret.GetSecret(ctx, helper, instance.Spec.TransportURLSecret, instance.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	Log.Info(fmt.Sprintf("[KeystoneListener] Defining deployment '%s'", instance.Name))
 	// Define a new Deployment object
-	deplDef := barbicankeystonelistener.Deployment(instance, inputHash, serviceLabels, serviceAnnotations, topology)
+	deplDef := barbicankeystonelistener.Deployment(instance, inputHash, serviceLabels, serviceAnnotations, topology,
+		string(transportURLSecret.Data[TransportURL]))
 	Log.Info(fmt.Sprintf("[KeystoneListener] Getting deployment '%s'", instance.Name))
 	depl := deployment.NewDeployment(
 		deplDef,
 		time.Duration(5)*time.Second,
 	)
 	Log.Info(fmt.Sprintf("[KeystoneListener] Got deployment '%s'", instance.Name))
-	ctrlResult, err = depl.CreateOrPatch(ctx, helper)
+	ctrlResult, err = barbican.CreateOrPatchDeployment(ctx, helper, depl, deplDef)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -647,7 +677,16 @@ func (r *BarbicanKeystoneListenerReconciler) reconcileNormal(ctx context.Context
 		return ctrlResult, nil
 	}
 
+	ctrlResult, err = reconcilePodDisruptionBudget(ctx, helper, &instance.Status.Conditions,
+		deplDef.Name, deplDef.Namespace, serviceLabels, deplDef.Spec.Replicas, instance.Spec.PodDisruptionBudget)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
 	deploy := depl.GetDeployment()
+	setRolloutCondition(&instance.Status.Conditions, deploy)
 	if deploy.Generation == deploy.Status.ObservedGeneration {
 		instance.Status.ReadyCount = deploy.Status.ReadyReplicas
 	}
@@ -678,7 +717,7 @@ func (r *BarbicanKeystoneListenerReconciler) reconcileNormal(ctx context.Context
 	// Replicas > ReadyReplicas.
 	// In addition, make sure the controller sees the last Generation
 	// by comparing it with the ObservedGeneration.
-	if deployment.IsReady(deploy) {
+	if barbican.IsRolledOut(deploy) {
 		oldDepName := fmt.Sprintf("%s-keystone-listener", instance.Name)
 		if err := cleanupOldDeployment(ctx, r.Client, instance, oldDepName); err != nil {
 			return ctrl.Result{}, err
@@ -693,6 +732,8 @@ func (r *BarbicanKeystoneListenerReconciler) reconcileNormal(ctx context.Context
 	}
 	// create Deployment - end
 
+	// Update the lastObserved generation before evaluating conditions
+	instance.Status.ObservedGeneration = instance.Generation
 	// We reached the end of the Reconcile, update the Ready condition based on
 	// the sub conditions
 	if instance.Status.Conditions.AllSubConditionIsTrue() {
@@ -781,6 +822,7 @@ func (r *BarbicanKeystoneListenerReconciler) SetupWithManager(mgr ctrl.Manager)
 		// Owns(&corev1.Service{}).
 		// Owns(&corev1.Secret{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		// Owns(&routev1.Route{}).
 		Watches(
 			&corev1.Secret{},
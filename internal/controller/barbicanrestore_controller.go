@@ -0,0 +1,493 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	common_rbac "github.com/openstack-k8s-operators/lib-common/modules/common/rbac"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BarbicanRestoreReconciler reconciles a BarbicanRestore object
+type BarbicanRestoreReconciler struct {
+	client.Client
+	Kclient kubernetes.Interface
+	Scheme  *runtime.Scheme
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *BarbicanRestoreReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("BarbicanRestore")
+}
+
+// +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanrestores/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="security.openshift.io",resourceNames=anyuid,resources=securitycontextconstraints,verbs=use
+
+// Reconcile BarbicanRestore
+func (r *BarbicanRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &barbicanv1beta1.BarbicanRestore{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Object not found
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	// a restore is run once, a completed restore never loads the dump again
+	if instance.IsReady() {
+		return ctrl.Result{}, nil
+	}
+	Log.Info(fmt.Sprintf("Reconciling BarbicanRestore %s", instance.Name))
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// initialize status if Conditions is nil, but do not reset if it already
+	// exists
+	isNewInstance := instance.Status.Conditions == nil
+	if isNewInstance {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// Always patch the instance status when exiting this function so we can
+	// persist any changes.
+	defer func() {
+		// Don't update the status, if reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("panic during reconcile %v\n", r))
+			panic(r)
+		}
+		condition.RestoreLastTransitionTimes(
+			&instance.Status.Conditions, savedConditions)
+		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	// Initialize Conditions
+	cl := condition.CreateList(
+		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
+		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(condition.ServiceAccountReadyCondition, condition.InitReason, condition.ServiceAccountReadyInitMessage),
+		condition.UnknownCondition(condition.RoleReadyCondition, condition.InitReason, condition.RoleReadyInitMessage),
+		condition.UnknownCondition(condition.RoleBindingReadyCondition, condition.InitReason, condition.RoleBindingReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanRestoreKEKReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanRestoreKEKReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanRestoreDBReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanRestoreDBReadyInitMessage),
+	)
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	if isNewInstance {
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+func (r *BarbicanRestoreReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *barbicanv1beta1.BarbicanRestore,
+	helper *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	//
+	// check the Barbican and the private key
+	//
+	barbicanInstance := &barbicanv1beta1.Barbican{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Spec.BarbicanName, Namespace: instance.Namespace}, barbicanInstance)
+	if k8s_errors.IsNotFound(err) {
+		Log.Info(fmt.Sprintf("Barbican %s not found", instance.Spec.BarbicanName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			condition.InputReadyWaitingMessage))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the bundle holds a mysqldump
+	if barbicanInstance.Spec.DatabaseType == barbicanv1beta1.DatabaseTypePostgreSQL {
+		err = fmt.Errorf("%w, the databaseType is %s", ErrBackupDatabaseType, barbicanInstance.Spec.DatabaseType)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	privateKey, result, err := getBackupKey(ctx, helper, instance.Namespace,
+		instance.Spec.PrivateKeySecret, barbicanv1beta1.RestorePrivateKeySelector, &instance.Status.Conditions)
+	if err != nil || (result != ctrl.Result{}) {
+		return result, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	if instance.Status.StartTime == nil {
+		now := metav1.Now()
+		instance.Status.StartTime = &now
+	}
+
+	serviceLabels := map[string]string{
+		common.AppSelector: barbican.ServiceName,
+	}
+
+	//
+	// recreate the KEK Secret, the Barbican waits for it before rendering its
+	// config
+	//
+	ctrlResult, err := r.reconcileKEK(ctx, instance, barbicanInstance, helper, serviceLabels, privateKey)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	//
+	// load the database dump once the database of the Barbican exists, the
+	// Barbican waits for it before running its db-sync
+	//
+	if !barbicanInstance.Status.Conditions.IsTrue(condition.DBReadyCondition) ||
+		!barbicanInstance.Status.Conditions.IsTrue(condition.ServiceConfigReadyCondition) {
+		Log.Info(fmt.Sprintf("BarbicanRestore %s - waiting for the database of Barbican %s", instance.Name, barbicanInstance.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreDBReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanRestoreDBReadyWaitingMessage,
+			barbicanInstance.Name))
+		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+	}
+
+	databaseAccount, _, err := mariadbv1.GetAccountAndSecret(
+		ctx, helper, barbicanInstance.Spec.DatabaseAccount, instance.Namespace)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreDBReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanRestoreDBReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	dbHash := instance.Status.Hash[barbicanv1beta1.RestoreDBHash]
+	jobDef := barbican.RestoreDBJob(instance, barbicanInstance, serviceLabels, databaseAccount)
+
+	dbJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.RestoreDBHash,
+		barbicanInstance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		dbHash,
+	)
+	ctrlResult, err = dbJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreDBReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanRestoreDBReadyRunningMessage,
+			instance.Spec.Bundle))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreDBReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanRestoreDBReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if dbJob.HasChanged() {
+		instance.Status.Hash[barbicanv1beta1.RestoreDBHash] = dbJob.GetHash()
+		Log.Info(fmt.Sprintf("BarbicanRestore %s - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.RestoreDBHash]))
+	}
+
+	now := metav1.Now()
+	instance.Status.CompletionTime = &now
+	instance.Status.Conditions.MarkTrue(barbicanv1beta1.BarbicanRestoreDBReadyCondition,
+		barbicanv1beta1.BarbicanRestoreDBReadyMessage, instance.Spec.Bundle)
+	Log.Info(fmt.Sprintf("BarbicanRestore %s - bundle %s restored", instance.Name, instance.Spec.Bundle))
+
+	if instance.Status.Conditions.AllSubConditionIsTrue() {
+		instance.Status.Conditions.MarkTrue(
+			condition.ReadyCondition, condition.ReadyMessage)
+	}
+	return ctrl.Result{}, nil
+}
+
+// barbicanRestoreRbacRules - the KEK restore Job may only patch the restore
+// Secret it copies the encrypted KEKs to
+func barbicanRestoreRbacRules(instance *barbicanv1beta1.BarbicanRestore) []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{"security.openshift.io"},
+			ResourceNames: []string{"anyuid"},
+			Resources:     []string{"securitycontextconstraints"},
+			Verbs:         []string{"use"},
+		},
+		{
+			APIGroups:     []string{""},
+			ResourceNames: []string{barbican.GetRestoreKEKSecretName(instance.Name)},
+			Resources:     []string{"secrets"},
+			Verbs:         []string{"get", "patch"},
+		},
+	}
+}
+
+// reconcileKEK runs the Job copying the encrypted KEKs from the bundle to the
+// restore Secret, and restores the KEKs to the KEK Secret of the Barbican. An
+// existing KEK is never overwritten, the secrets of the dump could not be
+// decrypted anymore.
+func (r *BarbicanRestoreReconciler) reconcileKEK(
+	ctx context.Context,
+	instance *barbicanv1beta1.BarbicanRestore,
+	barbicanInstance *barbicanv1beta1.Barbican,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	privateKey []byte,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if instance.Status.Hash[barbicanv1beta1.RestoreKEKHash] != "" {
+		return ctrl.Result{}, nil
+	}
+
+	ctrlResult, err := common_rbac.ReconcileRbac(ctx, helper, instance, barbicanRestoreRbacRules(instance))
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	// the Job patches the Secret, it cannot create it
+	restoreSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      barbican.GetRestoreKEKSecretName(instance.Name),
+			Namespace: instance.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrPatch(ctx, r.Client, restoreSecret, func() error {
+		restoreSecret.Labels = util.MergeStringMaps(restoreSecret.Labels, serviceLabels)
+		return controllerutil.SetControllerReference(instance, restoreSecret, r.Scheme)
+	})
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanRestoreKEKReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	jobDef := barbican.RestoreKEKJob(instance, barbicanInstance, serviceLabels)
+	kekJob := job.NewJob(
+		jobDef,
+		barbicanv1beta1.RestoreKEKHash,
+		barbicanInstance.Spec.PreserveJobs,
+		time.Duration(5)*time.Second,
+		"",
+	)
+	ctrlResult, err = kekJob.DoJob(
+		ctx,
+		helper,
+	)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			barbicanv1beta1.BarbicanRestoreKEKReadyRunningMessage,
+			instance.Spec.Bundle))
+		return ctrlResult, nil
+	}
+	if err == nil {
+		// read past the cache, it may not have seen the patch of the Job yet
+		restoreSecret, err = r.Kclient.CoreV1().Secrets(instance.Namespace).Get(ctx, restoreSecret.Name, metav1.GetOptions{})
+	}
+	if err == nil {
+		err = r.restoreKEKSecret(ctx, instance, barbicanInstance, restoreSecret.Data, privateKey)
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			barbicanv1beta1.BarbicanRestoreKEKReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Hash[barbicanv1beta1.RestoreKEKHash] = kekJob.GetHash()
+	Log.Info(fmt.Sprintf("BarbicanRestore %s - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[barbicanv1beta1.RestoreKEKHash]))
+
+	if instance.Status.KEKSecret == "" {
+		instance.Status.Conditions.MarkTrue(barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+			barbicanv1beta1.BarbicanRestoreKEKReadyNotNeededMessage, instance.Spec.Bundle)
+	} else {
+		instance.Status.Conditions.MarkTrue(barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+			barbicanv1beta1.BarbicanRestoreKEKReadyMessage, instance.Status.KEKSecret)
+	}
+	return ctrl.Result{}, nil
+}
+
+// restoreKEKSecret decrypts the KEKs the Job copied to the restore Secret, and
+// adds them to the KEK Secret of the Barbican, creating it when it does not
+// exist. The Secret is often shared with the other services, its other fields
+// are left alone. The Secret is not owned by the restore, it outlives it.
+func (r *BarbicanRestoreReconciler) restoreKEKSecret(
+	ctx context.Context,
+	instance *barbicanv1beta1.BarbicanRestore,
+	barbicanInstance *barbicanv1beta1.Barbican,
+	restoreData map[string][]byte,
+	privateKey []byte,
+) error {
+	if _, ok := restoreData[barbican.RestoreNoKEKKey]; ok {
+		instance.Status.KEKSecret = ""
+		return nil
+	}
+	encrypted, ok := restoreData[barbican.BackupKEKFileName]
+	if !ok {
+		return ErrRestoreKEKMissing
+	}
+
+	data, err := barbican.DecryptKEKSecret(encrypted, privateKey)
+	if err != nil {
+		return err
+	}
+
+	kekSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      barbicanInstance.Spec.SimpleCryptoBackendSecret,
+			Namespace: instance.Namespace,
+		},
+	}
+	err = r.Get(ctx, types.NamespacedName{Name: kekSecret.Name, Namespace: kekSecret.Namespace}, kekSecret)
+	if k8s_errors.IsNotFound(err) {
+		kekSecret.Data = data
+		err = r.Create(ctx, kekSecret)
+		if err != nil {
+			return err
+		}
+		instance.Status.KEKSecret = kekSecret.Name
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(kekSecret.DeepCopy())
+	missing := false
+	for field, value := range data {
+		existing, ok := kekSecret.Data[field]
+		if !ok {
+			if kekSecret.Data == nil {
+				kekSecret.Data = map[string][]byte{}
+			}
+			kekSecret.Data[field] = value
+			missing = true
+		} else if !bytes.Equal(existing, value) {
+			return fmt.Errorf("%w: %s", ErrRestoreKEKSecretDiff, kekSecret.Name)
+		}
+	}
+	if missing {
+		err = r.Patch(ctx, kekSecret, patch)
+		if err != nil {
+			return err
+		}
+	}
+
+	instance.Status.KEKSecret = kekSecret.Name
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BarbicanRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&barbicanv1beta1.BarbicanRestore{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Complete(r)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"path/filepath"
 	"slices"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
 	nad "github.com/openstack-k8s-operators/lib-common/modules/common/networkattachment"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pdb"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	"k8s.io/apimachinery/pkg/fields"
@@ -55,7 +57,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -75,6 +79,8 @@ func (r *BarbicanWorkerReconciler) GetLogger(ctx context.Context) logr.Logger {
 // +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanapis/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=barbican.openstack.org,resources=barbicanapis/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=topology.openstack.org,resources=topologies,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile BarbicanWorker
 func (r *BarbicanWorkerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
@@ -141,6 +147,8 @@ func (r *BarbicanWorkerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 		condition.UnknownCondition(condition.ServiceConfigReadyCondition, condition.InitReason, condition.ServiceConfigReadyInitMessage),
 		condition.UnknownCondition(condition.DeploymentReadyCondition, condition.InitReason, condition.DeploymentReadyInitMessage),
+		condition.UnknownCondition(condition.PDBReadyCondition, condition.InitReason, condition.PDBReadyInitMessage),
+		condition.UnknownCondition(barbicanv1beta1.BarbicanRolloutReadyCondition, condition.InitReason, barbicanv1beta1.BarbicanRolloutReadyInitMessage),
 		condition.UnknownCondition(condition.NetworkAttachmentsReadyCondition, condition.InitReason, condition.NetworkAttachmentsReadyInitMessage),
 		condition.UnknownCondition(condition.TLSInputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 	)
@@ -250,14 +258,14 @@ func (r *BarbicanWorkerReconciler) createHashOfInputHashes(
 	return hash, changed, nil
 }
 
-// generateServiceConfigs - create Secret which holds the service configuration
-// TODO add DefaultConfigOverwrite
+// generateServiceConfigs - create Secret which holds the service configuration,
+// returns the hash of the config-data Secret of the Barbican it is built from
 func (r *BarbicanWorkerReconciler) generateServiceConfigs(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *barbicanv1beta1.BarbicanWorker,
 	envVars *map[string]env.Setter,
-) error {
+) (string, error) {
 	Log := r.GetLogger(ctx)
 	Log.Info("[Worker] generateServiceConfigs - reconciling")
 	labels := labels.GetLabels(instance, labels.GetGroupLabel(barbican.ServiceName), map[string]string{})
@@ -271,12 +279,14 @@ func (r *BarbicanWorkerReconciler) generateServiceConfigs(
 	// CustomConfigFileName) from the Secret generated by the top level
 	// barbican controller, and add them to this service specific Secret.
 	owner := barbican.GetOwningBarbicanName(instance)
+	barbicanConfigHash := ""
 	if owner != "" {
 		barbicanSecretName := owner + "-config-data"
-		barbicanSecret, _, err := secret.GetSecret(ctx, h, barbicanSecretName, instance.Namespace)
+		barbicanSecret, hash, err := secret.GetSecret(ctx, h, barbicanSecretName, instance.Namespace)
 		if err != nil {
-			return err
+			return "", err
 		}
+		barbicanConfigHash = hash
 		customData[barbican.DefaultsConfigFileName] = string(barbicanSecret.Data[barbican.DefaultsConfigFileName])
 		customData[barbican.CustomConfigFileName] = string(barbicanSecret.Data[barbican.CustomConfigFileName])
 
@@ -289,7 +299,12 @@ func (r *BarbicanWorkerReconciler) generateServiceConfigs(
 			}
 		}
 
-		// TODO(alee) Get custom config overwrites from the parent barbican
+		ownerInstance := &barbicanv1beta1.Barbican{}
+		err = h.GetClient().Get(ctx, types.NamespacedName{Name: owner, Namespace: instance.Namespace}, ownerInstance)
+		if err != nil {
+			return "", err
+		}
+		maps.Copy(customData, ownerInstance.Spec.DefaultConfigOverwrite)
 	}
 
 	maps.Copy(customData, instance.Spec.DefaultConfigOverwrite)
@@ -298,7 +313,7 @@ func (r *BarbicanWorkerReconciler) generateServiceConfigs(
 	for _, secretName := range instance.Spec.CustomServiceConfigSecrets {
 		secret, _, err := secret.GetSecret(ctx, h, secretName, instance.Namespace)
 		if err != nil {
-			return err
+			return "", err
 		}
 		for _, data := range secret.Data {
 			customSecrets += string(data) + "\n"
@@ -322,11 +337,17 @@ func (r *BarbicanWorkerReconciler) generateServiceConfigs(
 		}
 	}
 
+	// the Barbican controller merges the top level logging into the one of
+	// the service, a logging.conf of the defaultConfigOverwrite replaces the
+	// rendered one
+	maps.Copy(templateParameters, barbican.GetLoggingTemplateParameters(instance.Spec.Logging))
+	templateParameters["LogConfigAppend"] = filepath.Join(barbican.ConfigOverwriteDir, barbican.LoggingConfigFileName)
+
 	// To avoid a json parsing error in kolla files, we always need to set PKCS11ClientDataPath
 	// This gets overridden in the PKCS11 section below if needed.
 	templateParameters["PKCS11ClientDataPath"] = barbicanv1beta1.DefaultPKCS11ClientDataPath
 
-	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, labels, false)
+	return barbicanConfigHash, GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, serviceAdditionalTemplates, labels, false)
 }
 
 func (r *BarbicanWorkerReconciler) reconcileInit(
@@ -374,6 +395,11 @@ func (r *BarbicanWorkerReconciler) reconcileDelete(ctx context.Context, instance
 		return ctrlResult, err
 	}
 
+	// Remove the PodDisruptionBudget of the service pods
+	if err := pdb.DeletePDBWithName(ctx, helper, instance.Name, instance.Namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Service is deleted so remove the finalizer.
 	controllerutil.RemoveFinalizer(instance, helper.GetFinalizer())
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' delete successfully", instance.Name))
@@ -428,14 +454,53 @@ func (r *BarbicanWorkerReconciler) reconcileNormal(ctx context.Context, instance
 			return ctrlResult, err
 		}
 
-		// check for PKCS11 secret holding the PKCS11 Client Data
-		Log.Info(fmt.Sprintf("[Worker] Verify secret '%s'", instance.Spec.PKCS11.ClientDataSecret))
-		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.ClientDataSecret, []string{}, &configVars)
+		// check for PKCS11 secret holding the PKCS11 Client Data, a softhsm
+		// token does not need any
+		if instance.Spec.PKCS11.ClientDataSecret != "" {
+			Log.Info(fmt.Sprintf("[Worker] Verify secret '%s'", instance.Spec.PKCS11.ClientDataSecret))
+			ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.PKCS11.ClientDataSecret, []string{}, &configVars)
+			if err != nil {
+				return ctrlResult, err
+			}
+		}
+	}
+
+	// check KMIP secrets
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreKMIP) && instance.Spec.KMIP != nil {
+		// check for KMIP secret holding the client certificate and key
+		Log.Info(fmt.Sprintf("[Worker] Verify secret '%s'", instance.Spec.KMIP.ClientCertSecret))
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.KMIP.ClientCertSecret, []string{barbican.KMIPClientCertKey, barbican.KMIPClientKeyKey}, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+
+		// check for KMIP secret holding the CA certificate
+		Log.Info(fmt.Sprintf("[Worker] Verify secret '%s'", instance.Spec.KMIP.CASecret))
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.KMIP.CASecret, []string{barbican.KMIPCAKey}, &configVars)
 		if err != nil {
 			return ctrlResult, err
 		}
 	}
 
+	// check Vault secrets
+	if slices.Contains(instance.Spec.EnabledSecretStores, barbicanv1beta1.SecretStoreVault) && instance.Spec.Vault != nil {
+		// check for Vault secret holding the credentials
+		Log.Info(fmt.Sprintf("[Worker] Verify secret '%s'", instance.Spec.Vault.AuthSecret))
+		ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.Vault.AuthSecret, barbican.GetVaultAuthSecretKeys(instance.Spec.Vault.AuthMethod), &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+
+		// check for Vault secret holding the CA certificate
+		if instance.Spec.Vault.CASecret != "" {
+			Log.Info(fmt.Sprintf("[Worker] Verify secret '%s'", instance.Spec.Vault.CASecret))
+			ctrlResult, err = r.verifySecret(ctx, helper, instance, instance.Spec.Vault.CASecret, []string{barbican.VaultCAKey}, &configVars)
+			if err != nil {
+				return ctrlResult, err
+			}
+		}
+	}
+
 	//check CustomServiceConfigSecrets
 	for _, v := range instance.Spec.CustomServiceConfigSecrets {
 		Log.Info(fmt.Sprintf("[Worker] Verify secret '%s' from CustomServiceConfigSecrets", v))
@@ -492,7 +557,7 @@ func (r *BarbicanWorkerReconciler) reconcileNormal(ctx context.Context, instance
 	//
 	// create custom config for this barbican service
 	//
-	err = r.generateServiceConfigs(ctx, helper, instance, &configVars)
+	barbicanConfigHash, err := r.generateServiceConfigs(ctx, helper, instance, &configVars)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -618,16 +683,38 @@ func (r *BarbicanWorkerReconciler) reconcileNormal(ctx context.Context, instance
 		return ctrl.Result{}, fmt.Errorf("waiting for Topology requirements: %w", err)
 	}
 
+	// the readiness probe checks the connection to the RabbitMQ hosts of the
+	// transport URL
+	transportURLSecret, _, err := secret.GetSecret(ctx, helper, instance.Spec.TransportURLSecret, instance.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	Log.Info(fmt.Sprintf("[Worker] Defining deployment '%s'", instance.Name))
 	// Define a new Deployment object
-	deplDef := barbicanworker.Deployment(instance, inputHash, serviceLabels, serviceAnnotations, topology)
+	deplDef := barbicanworker.Deployment(instance, inputHash, serviceLabels, serviceAnnotations, topology,
+		string(transportURLSecret.Data[TransportURL]))
+	// an autoscaled Deployment keeps the replicas set by its
+	// HorizontalPodAutoscaler
+	if instance.Spec.Autoscaling != nil {
+		deplDef.Spec.Replicas, err = getAutoscaledReplicas(ctx, helper, deplDef.Name, deplDef.Namespace, instance.Spec.Autoscaling)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.DeploymentReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.DeploymentReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+	}
 	Log.Info(fmt.Sprintf("[Worker] Getting deployment '%s'", instance.Name))
 	depl := deployment.NewDeployment(
 		deplDef,
 		time.Duration(5)*time.Second,
 	)
 	Log.Info(fmt.Sprintf("[Worker] Got deployment '%s'", instance.Name))
-	ctrlResult, err = depl.CreateOrPatch(ctx, helper)
+	ctrlResult, err = barbican.CreateOrPatchDeployment(ctx, helper, depl, deplDef)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -645,7 +732,27 @@ func (r *BarbicanWorkerReconciler) reconcileNormal(ctx context.Context, instance
 		return ctrlResult, nil
 	}
 
+	err = reconcileHorizontalPodAutoscaler(ctx, helper, deplDef.Name, deplDef.Namespace, serviceLabels, instance.Spec.Autoscaling)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	ctrlResult, err = reconcilePodDisruptionBudget(ctx, helper, &instance.Status.Conditions,
+		deplDef.Name, deplDef.Namespace, serviceLabels, deplDef.Spec.Replicas, instance.Spec.PodDisruptionBudget)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
 	deploy := depl.GetDeployment()
+	setRolloutCondition(&instance.Status.Conditions, deploy)
 	if deploy.Generation == deploy.Status.ObservedGeneration {
 		instance.Status.ReadyCount = deploy.Status.ReadyReplicas
 	}
@@ -676,12 +783,15 @@ func (r *BarbicanWorkerReconciler) reconcileNormal(ctx context.Context, instance
 	// Replicas > ReadyReplicas.
 	// In addition, make sure the controller sees the last Generation
 	// by comparing it with the ObservedGeneration.
-	if deployment.IsReady(deploy) {
+	if barbican.IsRolledOut(deploy) {
 		oldDepName := fmt.Sprintf("%s-worker", instance.Name)
 		if err := cleanupOldDeployment(ctx, r.Client, instance, oldDepName); err != nil {
 			return ctrl.Result{}, err
 		}
 		instance.Status.Conditions.MarkTrue(condition.DeploymentReadyCondition, condition.DeploymentReadyMessage)
+		// the pods run with the config of the Barbican, the Barbican waits
+		// for it before rewrapping or migrating the secrets
+		instance.Status.Hash[barbicanv1beta1.ConfigRolledOutHash] = barbicanConfigHash
 	} else {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -691,6 +801,8 @@ func (r *BarbicanWorkerReconciler) reconcileNormal(ctx context.Context, instance
 	}
 	// create Deployment - end
 
+	// Update the lastObserved generation before evaluating conditions
+	instance.Status.ObservedGeneration = instance.Generation
 	// We reached the end of the Reconcile, update the Ready condition based on
 	// the sub conditions
 	if instance.Status.Conditions.AllSubConditionIsTrue() {
@@ -773,6 +885,54 @@ func (r *BarbicanWorkerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	// index kmipClientCertSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanWorker{}, kmipClientCertSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanWorker)
+		if cr.Spec.KMIP == nil || cr.Spec.KMIP.ClientCertSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KMIP.ClientCertSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index kmipCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanWorker{}, kmipCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanWorker)
+		if cr.Spec.KMIP == nil || cr.Spec.KMIP.CASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KMIP.CASecret}
+	}); err != nil {
+		return err
+	}
+
+	// index vaultAuthSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanWorker{}, vaultAuthSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanWorker)
+		if cr.Spec.Vault == nil || cr.Spec.Vault.AuthSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Vault.AuthSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index vaultCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanWorker{}, vaultCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*barbicanv1beta1.BarbicanWorker)
+		if cr.Spec.Vault == nil || cr.Spec.Vault.CASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Vault.CASecret}
+	}); err != nil {
+		return err
+	}
+
 	// index topologyField
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &barbicanv1beta1.BarbicanWorker{}, topologyField, func(rawObj client.Object) []string {
 		// Extract the topology name from the spec, if one is provided
@@ -803,6 +963,8 @@ func (r *BarbicanWorkerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// Owns(&corev1.Service{}).
 		// Owns(&corev1.Secret{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
 		// Owns(&routev1.Route{}).
 		Watches(
 			&corev1.Secret{},
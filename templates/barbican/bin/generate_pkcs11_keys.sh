@@ -17,11 +17,30 @@ set -xe
 
 {{- if and (index . "PKCS11Enabled") .PKCS11Enabled }}
 
+{{- if .PKCS11MKEKLabel }}
+mkek_label="{{ .PKCS11MKEKLabel }}"
+hmac_label="{{ .PKCS11HMACLabel }}"
+{{- else }}
+# deprecated, the labels should be set in the pkcs11 section of the spec
 eval mkek_label=$(crudini --get /etc/barbican/barbican.conf.d/01-custom.conf p11_crypto_plugin mkek_label)
+eval hmac_label=$(crudini --get /etc/barbican/barbican.conf.d/01-custom.conf p11_crypto_plugin hmac_label)
+{{- end }}
+
 echo "Creating  MKEK label $mkek_label"
-barbican-manage hsm check_mkek --label $mkek_label || barbican-manage hsm gen_mkek --label $mkek_label
+barbican-manage hsm check_mkek --label "$mkek_label" || barbican-manage hsm gen_mkek --label "$mkek_label"{{ if (index . "PKCS11MKEKLength") }} --length {{ .PKCS11MKEKLength }}{{ end }}
 
-eval hmac_label=$(crudini --get /etc/barbican/barbican.conf.d/01-custom.conf p11_crypto_plugin hmac_label)
 echo "Creating  HMAC label $hmac_label"
-barbican-manage hsm check_hmac --label $hmac_label || barbican-manage hsm gen_hmac --label $hmac_label
+barbican-manage hsm check_hmac --label "$hmac_label" || barbican-manage hsm gen_hmac --label "$hmac_label"{{ if (index . "PKCS11HMACLength") }} --length {{ .PKCS11HMACLength }}{{ end }}
+
+if [ "${PKCS11_REWRAP_PKEK:-false}" = "true" ]; then
+    # the project KEKs record the labels they were wrapped with, so they can
+    # be unwrapped with the previous keys and rewrapped with the new ones
+    echo "Rewrapping project KEKs with MKEK label $mkek_label and HMAC label $hmac_label"
+    barbican-manage hsm rewrap_pkek
+fi
+{{- if eq .PKCS11Vendor "softhsm" }}
+
+# the keys are generated as root, hand the token store back to the services
+chown -R barbican:barbican {{ .SoftHSMTokenPath }}
+{{- end }}
 {{- end }}
@@ -0,0 +1,27 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+# no xtrace, the PINs are passed on the command line
+set -e
+
+token_label="${SOFTHSM_TOKEN_LABEL}"
+
+if softhsm2-util --show-slots | grep -q "^ *Label: *${token_label} *$"; then
+    echo "SoftHSM token $token_label is already initialised"
+    exit 0
+fi
+
+echo "Initialising SoftHSM token $token_label"
+softhsm2-util --init-token --free --label "$token_label" --pin "$SOFTHSM_PIN" --so-pin "$SOFTHSM_SO_PIN"
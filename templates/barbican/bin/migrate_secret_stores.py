@@ -0,0 +1,137 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+# Re-encrypts the secrets stored by the crypto plugin of the source secret
+# store with the crypto plugin of the target secret store. Both stores use the
+# store_crypto secret store plugin, so only the encrypted datum of a secret
+# changes, its secret store metadata stays the same.
+#
+# Only the repositories and the crypto plugin contract are used, the private
+# helpers of barbican.plugin.store_crypto may change with any release.
+
+import base64
+import sys
+
+from barbican.common import config
+from barbican.common import utils
+from barbican.model import models
+from barbican.model import repositories as repo
+from barbican.plugin.crypto import base as crypto
+from barbican.plugin.crypto import manager as crypto_manager
+
+# the progress is reported in the termination message of the container, the
+# operator records it in the status of the migration
+TERMINATION_LOG = '/dev/termination-log'
+
+CRYPTO_PLUGINS = {
+    'simple_crypto': 'barbican.plugin.crypto.simple_crypto.SimpleCryptoPlugin',
+    'pkcs11': 'barbican.plugin.crypto.p11_crypto.P11CryptoPlugin',
+}
+
+
+def get_crypto_plugin(store):
+    plugin_name = CRYPTO_PLUGINS[store]
+    for ext in crypto_manager.get_manager().extensions:
+        if utils.generate_fullname_for(ext.obj) == plugin_name:
+            return ext.obj
+    raise RuntimeError('crypto plugin of secret store %s is not enabled' % store)
+
+
+def find_or_create_kek(plugin, project):
+    """Returns the KEK of the project for the crypto plugin, bound to it."""
+    kek_repo = repo.get_kek_datum_repository()
+    kek_datum = kek_repo.find_or_create_kek_datum(
+        project, utils.generate_fullname_for(plugin))
+    kek_meta_dto = crypto.KEKMetaDTO(kek_datum)
+    if not kek_datum.bind_completed:
+        kek_meta_dto = plugin.bind_kek_metadata(kek_meta_dto)
+        kek_datum.bind_completed = True
+        kek_datum.algorithm = kek_meta_dto.algorithm
+        kek_datum.bit_length = kek_meta_dto.bit_length
+        kek_datum.mode = kek_meta_dto.mode
+        kek_datum.plugin_meta = kek_meta_dto.plugin_meta
+        kek_repo.save(kek_datum)
+    return kek_datum, kek_meta_dto
+
+
+def report_progress(total, migrated, failed):
+    try:
+        with open(TERMINATION_LOG, 'w') as log:
+            log.write('total=%d migrated=%d failed=%d' % (total, migrated, failed))
+    except OSError as e:
+        print('Failed to report the progress: %s' % e)
+
+
+def migrate_datum(session, datum, source_plugin, target_plugin):
+    secret = repo.get_secret_repository().get_secret_by_id(datum.secret_id)
+    project = repo.get_project_repository().get(secret.project_id)
+
+    secret_bytes = source_plugin.decrypt(
+        crypto.DecryptDTO(base64.b64decode(datum.cypher_text)),
+        crypto.KEKMetaDTO(datum.kek_meta_project),
+        datum.kek_meta_extended,
+        project.external_id)
+
+    kek_datum, kek_meta_dto = find_or_create_kek(target_plugin, project)
+    response_dto = target_plugin.encrypt(
+        crypto.EncryptDTO(secret_bytes), kek_meta_dto, project.external_id)
+
+    new_datum = models.EncryptedDatum(secret, kek_datum)
+    new_datum.content_type = datum.content_type
+    new_datum.cypher_text = base64.b64encode(response_dto.cypher_text)
+    new_datum.kek_meta_extended = response_dto.kek_meta_extended
+    session.delete(datum)
+    repo.get_encrypted_datum_repository().create_from(new_datum)
+
+
+def main(source, target):
+    config.parse_args(config.CONF)
+    repo.setup_database_engine_and_factory()
+
+    source_plugin = get_crypto_plugin(source)
+    target_plugin = get_crypto_plugin(target)
+
+    session = repo.get_session()
+    data = session.query(models.EncryptedDatum).join(
+        models.KEKDatum,
+        models.EncryptedDatum.kek_id == models.KEKDatum.id
+    ).filter(
+        models.KEKDatum.plugin_name == CRYPTO_PLUGINS[source],
+        models.EncryptedDatum.deleted == False  # noqa: E712
+    ).all()
+
+    print('Found %d secrets stored in %s' % (len(data), source))
+    failed = 0
+    report_progress(len(data), 0, failed)
+    for count, datum in enumerate(data, start=1):
+        try:
+            migrate_datum(session, datum, source_plugin, target_plugin)
+            repo.commit()
+        except Exception as e:
+            repo.rollback()
+            failed += 1
+            print('Failed to migrate secret %s: %s' % (datum.secret_id, e))
+        print('Migrated %d/%d secrets' % (count - failed, len(data)))
+        report_progress(len(data), count - failed, failed)
+
+    repo.clear()
+    if failed:
+        print('%d secrets could not be migrated to %s' % (failed, target))
+        return 1
+    return 0
+
+
+if __name__ == '__main__':
+    sys.exit(main(sys.argv[1], sys.argv[2]))
@@ -0,0 +1,23 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+set -xe
+
+# register the enabled secret stores, the source store is still rendered as
+# the global default while the secrets get migrated
+barbican-manage db sync_secret_stores
+
+echo "Migrating secrets from $SECRET_STORE_MIGRATION_SOURCE to $SECRET_STORE_MIGRATION_TARGET"
+python3 /bin/migrate_secret_stores.py "$SECRET_STORE_MIGRATION_SOURCE" "$SECRET_STORE_MIGRATION_TARGET"
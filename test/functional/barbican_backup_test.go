@@ -0,0 +1,497 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functional
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	barbicanv1beta1 "github.com/openstack-k8s-operators/barbican-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/barbican-operator/internal/barbican"
+	controllers "github.com/openstack-k8s-operators/barbican-operator/internal/controller"
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// simulateRestoreKEKJob copies the encrypted KEKs to the restore Secret and
+// completes the KEK restore Job, as envtest runs no pods
+func simulateRestoreKEKJob(data map[string][]byte) {
+	Eventually(func(g Gomega) {
+		secret := &corev1.Secret{}
+		g.Expect(k8sClient.Get(ctx, barbicanTest.BarbicanRestoreKEKSecret, secret)).To(Succeed())
+		secret.Data = data
+		g.Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+	th.SimulateJobSuccess(barbicanTest.BarbicanRestoreKEKJob)
+}
+
+// simulateBarbicanDatabase makes the database and the services the Barbican
+// depends on ready. The Jobs loading and dumping the database are simulated,
+// envtest has no MariaDB.
+func simulateBarbicanDatabase() {
+	DeferCleanup(
+		mariadb.DeleteDBService,
+		mariadb.CreateDBService(
+			barbicanTest.Instance.Namespace,
+			GetBarbican(barbicanTest.Instance).Spec.DatabaseInstance,
+			corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 3306}},
+			},
+		),
+	)
+	infra.SimulateTransportURLReady(barbicanTest.BarbicanTransportURL)
+	DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(barbicanTest.Instance.Namespace))
+	mariadb.SimulateMariaDBAccountCompleted(barbicanTest.BarbicanDatabaseAccount)
+	mariadb.SimulateMariaDBDatabaseCompleted(barbicanTest.BarbicanDatabaseName)
+}
+
+// localMariaDB - the MariaDB the backup and restore Jobs are run against, as
+// envtest has none. It is given by MARIADB_TEST_HOST, MARIADB_TEST_USER and
+// MARIADB_TEST_PASSWORD, MYSQL_TCP_PORT sets a port other than 3306.
+type localMariaDB struct {
+	host     string
+	user     string
+	password string
+	database string
+}
+
+// getLocalMariaDB skips the test when no local MariaDB or no mysql client is
+// available
+func getLocalMariaDB() localMariaDB {
+	db := localMariaDB{
+		host:     os.Getenv("MARIADB_TEST_HOST"),
+		user:     os.Getenv("MARIADB_TEST_USER"),
+		password: os.Getenv("MARIADB_TEST_PASSWORD"),
+		database: "barbican_restore_test",
+	}
+	if db.host == "" {
+		Skip("MARIADB_TEST_HOST is not set")
+	}
+	if db.user == "" {
+		db.user = "root"
+	}
+	for _, command := range []string{"mysql", "mysqldump", "gzip", "gunzip"} {
+		if _, err := exec.LookPath(command); err != nil {
+			Skip(fmt.Sprintf("%s is not installed", command))
+		}
+	}
+	return db
+}
+
+func (db localMariaDB) env() []string {
+	return append(os.Environ(), "MYSQL_PWD="+db.password)
+}
+
+// exec runs the statements in the database and returns the rows
+func (db localMariaDB) exec(statements string) string {
+	cmd := exec.Command("mysql", "-h", db.host, "-u", db.user, "-N", "-B", "-e", statements)
+	cmd.Env = db.env()
+	out, err := cmd.CombinedOutput()
+	Expect(err).ToNot(HaveOccurred(), string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// runJob runs the script of the Job on the host against the database. The
+// Secret volumes are written to temporary directories, the
+// PersistentVolumeClaim is the directory given, and the mount points in the
+// script are replaced by them.
+func (db localMariaDB) runJob(name types.NamespacedName, claimDir string) {
+	spec := th.GetJob(name).Spec.Template.Spec
+	container := spec.Containers[0]
+
+	volumeDirs := map[string]string{}
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			volumeDirs[volume.Name] = claimDir
+		case volume.Secret != nil:
+			dir := GinkgoT().TempDir()
+			secret := th.GetSecret(types.NamespacedName{Namespace: name.Namespace, Name: volume.Secret.SecretName})
+			files := map[string]string{}
+			for key := range secret.Data {
+				files[key] = key
+			}
+			if len(volume.Secret.Items) != 0 {
+				files = map[string]string{}
+				for _, item := range volume.Secret.Items {
+					files[item.Key] = item.Path
+				}
+			}
+			for key, path := range files {
+				Expect(os.WriteFile(filepath.Join(dir, path), secret.Data[key], 0o600)).To(Succeed())
+			}
+			volumeDirs[volume.Name] = dir
+		}
+	}
+
+	// the longest mount points first, they may start with a shorter one
+	mounts := slices.Clone(container.VolumeMounts)
+	slices.SortFunc(mounts, func(a, b corev1.VolumeMount) int {
+		return len(b.MountPath) - len(a.MountPath)
+	})
+	script := container.Args[len(container.Args)-1]
+	for _, mount := range mounts {
+		Expect(volumeDirs).To(HaveKey(mount.Name))
+		script = strings.ReplaceAll(script, mount.MountPath, filepath.Join(volumeDirs[mount.Name], mount.SubPath))
+	}
+
+	cmd := exec.Command("/bin/bash", "-c", script)
+	cmd.Env = db.env()
+	for _, envVar := range container.Env {
+		if envVar.ValueFrom == nil {
+			cmd.Env = append(cmd.Env, envVar.Name+"="+envVar.Value)
+		}
+	}
+	cmd.Env = append(cmd.Env, "DB_HOST="+db.host, "DB_USER="+db.user, "DB_NAME="+db.database)
+	out, err := cmd.CombinedOutput()
+	Expect(err).ToNot(HaveOccurred(), string(out))
+}
+
+var _ = Describe("BarbicanBackup controller", func() {
+	When("A BarbicanBackup of a Barbican is created", func() {
+		BeforeEach(func() {
+			DeferCleanup(k8sClient.Delete, ctx, CreateBarbicanMessageBusSecret(barbicanTest.Instance.Namespace, barbicanTest.RabbitmqSecretName))
+			DeferCleanup(th.DeleteInstance, CreateBarbican(barbicanTest.Instance, GetDefaultBarbicanSpec()))
+			DeferCleanup(k8sClient.Delete, ctx, CreateBarbicanSecret(barbicanTest.Instance.Namespace, SecretName))
+			DeferCleanup(k8sClient.Delete, ctx, CreateBackupKeySecret(barbicanTest.BarbicanBackupKeySecret))
+			DeferCleanup(th.DeleteInstance, CreateBarbicanBackup(barbicanTest.BarbicanBackup, map[string]any{
+				"barbicanName":          barbicanTest.Instance.Name,
+				"persistentVolumeClaim": "barbican-backup",
+				"publicKeySecret":       barbicanTest.BarbicanBackupKeySecret.Name,
+			}))
+		})
+
+		It("encrypts the KEK Secret to the public key", func() {
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanBackup,
+				ConditionGetterFunc(BarbicanBackupConditionGetter),
+				barbicanv1beta1.BarbicanBackupKEKReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf("KEK Secret %s encrypted", SecretName),
+			)
+			backup := GetBarbicanBackup(barbicanTest.BarbicanBackup)
+			Expect(backup.Status.KEKSecret).To(Equal(SecretName))
+			Expect(backup.Status.Bundle).To(MatchRegexp(`^%s-[0-9]{14}$`, barbicanTest.BarbicanBackup.Name))
+
+			keySecret := th.GetSecret(barbicanTest.BarbicanBackupKeySecret)
+			kekSecret := th.GetSecret(types.NamespacedName{Namespace: barbicanTest.Instance.Namespace, Name: SecretName})
+			encrypted := th.GetSecret(barbicanTest.BarbicanBackupKEKSecret)
+			data, err := barbican.DecryptKEKSecret(
+				encrypted.Data[barbican.BackupKEKFileName],
+				keySecret.Data[barbicanv1beta1.RestorePrivateKeySelector])
+			Expect(err).ToNot(HaveOccurred())
+			// only the KEK of the shared Secret is backed up
+			Expect(data).To(Equal(map[string][]byte{
+				"BarbicanSimpleCryptoKEK": kekSecret.Data["BarbicanSimpleCryptoKEK"],
+			}))
+
+			// the bundle waits for the database
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanBackup,
+				ConditionGetterFunc(BarbicanBackupConditionGetter),
+				barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf("Backup bundle is waiting for the database of Barbican %s", barbicanTest.Instance.Name),
+			)
+		})
+
+		It("writes the bundle once the database is ready", func() {
+			simulateBarbicanDatabase()
+
+			backupJob := th.GetJob(barbicanTest.BarbicanBackupJob)
+			backup := GetBarbicanBackup(barbicanTest.BarbicanBackup)
+			Expect(backupJob.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "BACKUP_BUNDLE", Value: backup.Status.Bundle}))
+			Expect(backupJob.Spec.Template.Spec.Volumes).To(ContainElements(
+				HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", "barbican-backup"),
+				HaveField("VolumeSource.Secret.SecretName", barbicanTest.BarbicanBackupKEKSecret.Name),
+				HaveField("VolumeSource.Secret.SecretName", barbicanTest.BarbicanConfigSecret.Name),
+			))
+			th.ExpectCondition(
+				barbicanTest.BarbicanBackup,
+				ConditionGetterFunc(BarbicanBackupConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+
+			th.SimulateJobSuccess(barbicanTest.BarbicanBackupJob)
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanBackup,
+				ConditionGetterFunc(BarbicanBackupConditionGetter),
+				barbicanv1beta1.BarbicanBackupBundleReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf("Backup bundle %s written", backup.Status.Bundle),
+			)
+			th.ExpectCondition(
+				barbicanTest.BarbicanBackup,
+				ConditionGetterFunc(BarbicanBackupConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(GetBarbicanBackup(barbicanTest.BarbicanBackup).Status.CompletionTime).ToNot(BeNil())
+		})
+	})
+
+	When("A BarbicanRestore of a Barbican is created", func() {
+		var keySecret *corev1.Secret
+
+		BeforeEach(func() {
+			keySecret = CreateBackupKeySecret(barbicanTest.BarbicanBackupKeySecret)
+			DeferCleanup(k8sClient.Delete, ctx, keySecret)
+			DeferCleanup(th.DeleteInstance, CreateBarbicanRestore(barbicanTest.BarbicanRestore, map[string]any{
+				"barbicanName":          barbicanTest.Instance.Name,
+				"persistentVolumeClaim": "barbican-backup",
+				"bundle":                "barbican-backup-20260101000000",
+				"privateKeySecret":      barbicanTest.BarbicanBackupKeySecret.Name,
+			}))
+			DeferCleanup(k8sClient.Delete, ctx, CreateBarbicanMessageBusSecret(barbicanTest.Instance.Namespace, barbicanTest.RabbitmqSecretName))
+			DeferCleanup(k8sClient.Delete, ctx, CreateBarbicanSecret(barbicanTest.Instance.Namespace, SecretName))
+		})
+
+		It("restores the KEK Secret and the database before the Barbican starts", func() {
+			DeferCleanup(th.DeleteInstance, CreateBarbican(barbicanTest.Instance, GetRestoreBarbicanSpec()))
+			restoredKEKSecret := types.NamespacedName{Namespace: barbicanTest.Instance.Namespace, Name: RestoredKEKSecretName}
+			kekData := map[string][]byte{
+				"BarbicanSimpleCryptoKEK": []byte("dGhpc2lzYW5ld2tla3RoaXNpc2FuZXdrZWt0aGlzaXM="),
+			}
+			encrypted, err := barbican.EncryptKEKSecret(kekData, keySecret.Data[barbicanv1beta1.BackupPublicKeySelector])
+			Expect(err).ToNot(HaveOccurred())
+
+			// the Barbican waits for the KEK Secret of the restore
+			th.ExpectConditionWithDetails(
+				barbicanTest.Instance,
+				ConditionGetterFunc(BarbicanConditionGetter),
+				controllers.RestoreReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf("Waiting for BarbicanRestore %s to restore the KEK Secret", barbicanTest.BarbicanRestore.Name),
+			)
+
+			restoreKEKJob := th.GetJob(barbicanTest.BarbicanRestoreKEKJob)
+			Expect(restoreKEKJob.Spec.Template.Spec.ServiceAccountName).To(Equal("barbicanrestore-" + barbicanTest.BarbicanRestore.Name))
+			Expect(restoreKEKJob.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "RESTORE_KEK_SECRET", Value: barbicanTest.BarbicanRestoreKEKSecret.Name}))
+			role := &rbacv1.Role{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: barbicanTest.Instance.Namespace,
+				Name:      "barbicanrestore-" + barbicanTest.BarbicanRestore.Name + "-role",
+			}, role)).To(Succeed())
+			Expect(role.Rules).To(ContainElement(And(
+				HaveField("Resources", ConsistOf("secrets")),
+				HaveField("ResourceNames", ConsistOf(barbicanTest.BarbicanRestoreKEKSecret.Name)),
+				HaveField("Verbs", ConsistOf("get", "patch")),
+			)))
+
+			simulateRestoreKEKJob(map[string][]byte{barbican.BackupKEKFileName: encrypted})
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf("KEK Secret %s restored", RestoredKEKSecretName),
+			)
+			Expect(th.GetSecret(restoredKEKSecret).Data).To(Equal(kekData))
+			DeferCleanup(k8sClient.Delete, ctx, th.GetSecret(restoredKEKSecret))
+
+			// the db-sync waits for the database dump
+			simulateBarbicanDatabase()
+			th.ExpectConditionWithDetails(
+				barbicanTest.Instance,
+				ConditionGetterFunc(BarbicanConditionGetter),
+				controllers.RestoreReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf("Waiting for BarbicanRestore %s to load the database dump", barbicanTest.BarbicanRestore.Name),
+			)
+			restoreDBJob := th.GetJob(barbicanTest.BarbicanRestoreDBJob)
+			Expect(restoreDBJob.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "BACKUP_BUNDLE", Value: "barbican-backup-20260101000000"}))
+			Consistently(func(g Gomega) {
+				err := k8sClient.Get(ctx, barbicanTest.BarbicanDBSync, &batchv1.Job{})
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			th.SimulateJobSuccess(barbicanTest.BarbicanRestoreDBJob)
+			th.ExpectCondition(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			th.GetJob(barbicanTest.BarbicanDBSync)
+			th.ExpectConditionWithDetails(
+				barbicanTest.Instance,
+				ConditionGetterFunc(BarbicanConditionGetter),
+				controllers.RestoreReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				controllers.RestoreReadyNotPendingMessage,
+			)
+		})
+
+		It("does not overwrite a KEK Secret with different data", func() {
+			DeferCleanup(th.DeleteInstance, CreateBarbican(barbicanTest.Instance, GetDefaultBarbicanSpec()))
+			encrypted, err := barbican.EncryptKEKSecret(map[string][]byte{
+				"BarbicanSimpleCryptoKEK": []byte("dGhpc2lzYW5ld2tla3RoaXNpc2FuZXdrZWt0aGlzaXM="),
+			}, keySecret.Data[barbicanv1beta1.BackupPublicKeySelector])
+			Expect(err).ToNot(HaveOccurred())
+
+			simulateRestoreKEKJob(map[string][]byte{barbican.BackupKEKFileName: encrypted})
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf("KEK restore error occurred %s: %s", controllers.ErrRestoreKEKSecretDiff, SecretName),
+			)
+			Consistently(func(g Gomega) {
+				g.Expect(GetBarbican(barbicanTest.Instance).Status.Conditions.IsFalse(controllers.RestoreReadyCondition)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("adds the KEKs to a KEK Secret shared with other services", func() {
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateSecret(
+				types.NamespacedName{Namespace: barbicanTest.Instance.Namespace, Name: RestoredKEKSecretName},
+				map[string][]byte{"NovaPassword": []byte("12345678")},
+			))
+			DeferCleanup(th.DeleteInstance, CreateBarbican(barbicanTest.Instance, GetRestoreBarbicanSpec()))
+			kekData := map[string][]byte{
+				"BarbicanSimpleCryptoKEK": []byte("dGhpc2lzYW5ld2tla3RoaXNpc2FuZXdrZWt0aGlzaXM="),
+			}
+			encrypted, err := barbican.EncryptKEKSecret(kekData, keySecret.Data[barbicanv1beta1.BackupPublicKeySelector])
+			Expect(err).ToNot(HaveOccurred())
+
+			simulateRestoreKEKJob(map[string][]byte{barbican.BackupKEKFileName: encrypted})
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf("KEK Secret %s restored", RestoredKEKSecretName),
+			)
+			Expect(th.GetSecret(types.NamespacedName{Namespace: barbicanTest.Instance.Namespace, Name: RestoredKEKSecretName}).Data).To(Equal(map[string][]byte{
+				"NovaPassword":            []byte("12345678"),
+				"BarbicanSimpleCryptoKEK": kekData["BarbicanSimpleCryptoKEK"],
+			}))
+		})
+
+		It("completes the KEK restore of a bundle without KEKs", func() {
+			DeferCleanup(th.DeleteInstance, CreateBarbican(barbicanTest.Instance, GetDefaultBarbicanSpec()))
+
+			simulateRestoreKEKJob(map[string][]byte{barbican.RestoreNoKEKKey: {}})
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf("No KEK Secret in bundle %s", "barbican-backup-20260101000000"),
+			)
+			Expect(GetBarbicanRestore(barbicanTest.BarbicanRestore).Status.KEKSecret).To(BeEmpty())
+		})
+	})
+
+	When("A bundle is written and restored with a local MariaDB", func() {
+		var db localMariaDB
+
+		BeforeEach(func() {
+			db = getLocalMariaDB()
+			db.exec(fmt.Sprintf(`DROP DATABASE IF EXISTS %[1]s;
+CREATE DATABASE %[1]s;
+CREATE TABLE %[1]s.secrets (id VARCHAR(36) PRIMARY KEY, name VARCHAR(255));
+INSERT INTO %[1]s.secrets VALUES ('1', 'secret-a'), ('2', 'secret-b');`, db.database))
+			DeferCleanup(db.exec, fmt.Sprintf("DROP DATABASE IF EXISTS %s", db.database))
+
+			DeferCleanup(k8sClient.Delete, ctx, CreateBarbicanMessageBusSecret(barbicanTest.Instance.Namespace, barbicanTest.RabbitmqSecretName))
+			DeferCleanup(th.DeleteInstance, CreateBarbican(barbicanTest.Instance, GetDefaultBarbicanSpec()))
+			DeferCleanup(k8sClient.Delete, ctx, CreateBarbicanSecret(barbicanTest.Instance.Namespace, SecretName))
+			DeferCleanup(k8sClient.Delete, ctx, CreateBackupKeySecret(barbicanTest.BarbicanBackupKeySecret))
+			DeferCleanup(th.DeleteInstance, CreateBarbicanBackup(barbicanTest.BarbicanBackup, map[string]any{
+				"barbicanName":          barbicanTest.Instance.Name,
+				"persistentVolumeClaim": "barbican-backup",
+				"publicKeySecret":       barbicanTest.BarbicanBackupKeySecret.Name,
+			}))
+			simulateBarbicanDatabase()
+		})
+
+		It("restores the database and the KEK of the bundle", func() {
+			claimDir := GinkgoT().TempDir()
+			db.runJob(barbicanTest.BarbicanBackupJob, claimDir)
+			th.SimulateJobSuccess(barbicanTest.BarbicanBackupJob)
+			th.ExpectCondition(
+				barbicanTest.BarbicanBackup,
+				ConditionGetterFunc(BarbicanBackupConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			bundle := filepath.Join(claimDir, GetBarbicanBackup(barbicanTest.BarbicanBackup).Status.Bundle)
+			Expect(filepath.Join(bundle, barbican.BackupDumpFileName)).To(BeARegularFile())
+			Expect(filepath.Join(bundle, "config", "my.cnf")).To(BeARegularFile())
+			encrypted, err := os.ReadFile(filepath.Join(bundle, barbican.BackupKEKFileName))
+			Expect(err).ToNot(HaveOccurred())
+
+			db.exec(fmt.Sprintf("DROP TABLE %s.secrets", db.database))
+			DeferCleanup(th.DeleteInstance, CreateBarbicanRestore(barbicanTest.BarbicanRestore, map[string]any{
+				"barbicanName":          barbicanTest.Instance.Name,
+				"persistentVolumeClaim": "barbican-backup",
+				"bundle":                filepath.Base(bundle),
+				"privateKeySecret":      barbicanTest.BarbicanBackupKeySecret.Name,
+			}))
+			// the KEK of the bundle is the one of the Barbican
+			simulateRestoreKEKJob(map[string][]byte{barbican.BackupKEKFileName: encrypted})
+			th.ExpectConditionWithDetails(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				barbicanv1beta1.BarbicanRestoreKEKReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf("KEK Secret %s restored", SecretName),
+			)
+
+			db.runJob(barbicanTest.BarbicanRestoreDBJob, claimDir)
+			th.SimulateJobSuccess(barbicanTest.BarbicanRestoreDBJob)
+			th.ExpectCondition(
+				barbicanTest.BarbicanRestore,
+				ConditionGetterFunc(BarbicanRestoreConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(db.exec(fmt.Sprintf("SELECT name FROM %s.secrets ORDER BY id", db.database))).To(
+				Equal("secret-a\nsecret-b"))
+		})
+	})
+})
@@ -47,6 +47,18 @@ const (
 	PKCS11LoginSecret = "pkcs11-login" // #nosec G101
 	// PKCS11ClientDataSecret -
 	PKCS11ClientDataSecret = "pkcs11-client-data" // #nosec G101
+	// KMIPClientCertSecret -
+	KMIPClientCertSecret = "kmip-client-cert" // #nosec G101
+	// KMIPCASecret -
+	KMIPCASecret = "kmip-ca" // #nosec G101
+	// KMIPHost -
+	KMIPHost = "kmip.example.com"
+	// VaultAuthSecret -
+	VaultAuthSecret = "vault-auth" // #nosec G101
+	// VaultCASecret -
+	VaultCASecret = "vault-ca" // #nosec G101
+	// VaultURL -
+	VaultURL = "https://vault.example.com:8200"
 )
 
 // BarbicanTestData is the data structure used to provide input data to envTest
@@ -65,7 +77,22 @@ type BarbicanTestData struct {
 	BarbicanDatabaseName                 types.NamespacedName
 	BarbicanDatabaseAccount              types.NamespacedName
 	BarbicanDBSync                       types.NamespacedName
+	BarbicanDBPurge                      types.NamespacedName
+	BarbicanDBBackup                     types.NamespacedName
+	BarbicanBackup                       types.NamespacedName
+	BarbicanBackupJob                    types.NamespacedName
+	BarbicanBackupKEKSecret              types.NamespacedName
+	BarbicanBackupKeySecret              types.NamespacedName
+	BarbicanRestore                      types.NamespacedName
+	BarbicanRestoreKEKJob                types.NamespacedName
+	BarbicanRestoreKEKSecret             types.NamespacedName
+	BarbicanRestoreDBJob                 types.NamespacedName
 	BarbicanPKCS11Prep                   types.NamespacedName
+	BarbicanSimpleCryptoKEKRewrap        types.NamespacedName
+	BarbicanPKCS11Rewrap                 types.NamespacedName
+	BarbicanSoftHSMInit                  types.NamespacedName
+	BarbicanSecretStoreMigration         types.NamespacedName
+	BarbicanSoftHSMTokens                types.NamespacedName
 	BarbicanAPI                          types.NamespacedName
 	BarbicanWorker                       types.NamespacedName
 	BarbicanWorkerDeployment             types.NamespacedName
@@ -128,10 +155,70 @@ func GetBarbicanTestData(barbicanName types.NamespacedName) BarbicanTestData {
 			Namespace: barbicanName.Namespace,
 			Name:      fmt.Sprintf("%s-db-sync", barbicanName.Name),
 		},
+		BarbicanDBPurge: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-db-purge", barbicanName.Name),
+		},
+		BarbicanDBBackup: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-db-backup", barbicanName.Name),
+		},
+		BarbicanBackup: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-backup", barbicanName.Name),
+		},
+		BarbicanBackupJob: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-backup-backup", barbicanName.Name),
+		},
+		BarbicanBackupKEKSecret: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-backup-kek", barbicanName.Name),
+		},
+		BarbicanBackupKeySecret: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      "barbican-backup-key",
+		},
+		BarbicanRestore: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-restore", barbicanName.Name),
+		},
+		BarbicanRestoreKEKJob: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-restore-restore-kek", barbicanName.Name),
+		},
+		BarbicanRestoreKEKSecret: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-restore-restore-kek", barbicanName.Name),
+		},
+		BarbicanRestoreDBJob: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-restore-restore-db", barbicanName.Name),
+		},
 		BarbicanPKCS11Prep: types.NamespacedName{
 			Namespace: barbicanName.Namespace,
 			Name:      fmt.Sprintf("%s-pkcs11-prep", barbicanName.Name),
 		},
+		BarbicanSimpleCryptoKEKRewrap: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-simple-crypto-kek-rewrap", barbicanName.Name),
+		},
+		BarbicanPKCS11Rewrap: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-pkcs11-rewrap", barbicanName.Name),
+		},
+		BarbicanSecretStoreMigration: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-secret-store-migration", barbicanName.Name),
+		},
+		BarbicanSoftHSMInit: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-softhsm-init", barbicanName.Name),
+		},
+		BarbicanSoftHSMTokens: types.NamespacedName{
+			Namespace: barbicanName.Namespace,
+			Name:      fmt.Sprintf("%s-softhsm-tokens", barbicanName.Name),
+		},
 		BarbicanAPIDeployment: types.NamespacedName{
 			Namespace: barbicanName.Namespace,
 			Name:      fmt.Sprintf("%s-api", barbicanName.Name),
@@ -277,6 +364,7 @@ func GetBarbicanTestData(barbicanName types.NamespacedName) BarbicanTestData {
 		BaseDefaultConfigOverwrite: map[string]string{
 			"policy.json":      "random base policy json stuff",
 			"base-custom.conf": "[DEFAULT]\nrandom_api_custom_config_override=true",
+			"logging.conf":     "[loggers]\nkeys=root",
 		},
 		APIDefaultConfigOverwrite: map[string]string{
 			"policy.json":     "random api policy json stuff",
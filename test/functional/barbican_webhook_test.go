@@ -18,6 +18,7 @@ package functional
 
 import (
 	"errors"
+	"maps"
 
 	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
 	. "github.com/onsi/gomega"    //revive:disable:dot-imports
@@ -28,51 +29,265 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// getBarbicanUnstructured - returns a Barbican with the spec as an
+// unstructured object, to submit fields the typed API does not accept
+func getBarbicanUnstructured(spec map[string]any, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "barbican.openstack.org/v1beta1",
+		"kind":       "Barbican",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}}
+}
+
+// expectBarbicanRejected - expects the webhook to reject the creation of a
+// Barbican with the spec, with a message containing all the substrings
+func expectBarbicanRejected(spec map[string]any, name string, substrings ...string) {
+	unstructuredObj := getBarbicanUnstructured(spec, name)
+	_, err := controllerutil.CreateOrPatch(
+		ctx, k8sClient, unstructuredObj, func() error { return nil })
+	Expect(err).Should(HaveOccurred())
+
+	var statusError *k8s_errors.StatusError
+	Expect(errors.As(err, &statusError)).To(BeTrue())
+	Expect(statusError.ErrStatus.Details.Kind).To(Equal("Barbican"))
+	for _, substring := range substrings {
+		Expect(statusError.ErrStatus.Message).To(ContainSubstring(substring))
+	}
+}
+
+// expectBarbicanUpdateRejected - creates a Barbican with the spec and expects
+// the webhook to reject the update of its spec, with a message containing all
+// the substrings
+func expectBarbicanUpdateRejected(
+	spec map[string]any,
+	name string,
+	update func(spec map[string]any),
+	substrings ...string,
+) {
+	unstructuredObj := getBarbicanUnstructured(spec, name)
+	_, err := controllerutil.CreateOrPatch(
+		ctx, k8sClient, unstructuredObj, func() error { return nil })
+	Expect(err).ShouldNot(HaveOccurred())
+
+	DeferCleanup(func() {
+		_ = k8sClient.Delete(ctx, unstructuredObj)
+	})
+
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, unstructuredObj)).Should(Succeed())
+		update(unstructuredObj.Object["spec"].(map[string]any))
+		err := k8sClient.Update(ctx, unstructuredObj)
+		g.Expect(err).Should(HaveOccurred())
+
+		var statusError *k8s_errors.StatusError
+		g.Expect(errors.As(err, &statusError)).To(BeTrue())
+		g.Expect(statusError.ErrStatus.Details.Kind).To(Equal("Barbican"))
+		for _, substring := range substrings {
+			g.Expect(statusError.ErrStatus.Message).To(ContainSubstring(substring))
+		}
+	}, timeout, interval).Should(Succeed())
+}
+
 var _ = Describe("Barbican webhook", func() {
 	It("rejects update to deprecated rabbitMqClusterName field", func() {
 		spec := GetDefaultBarbicanSpec()
 		spec["rabbitMqClusterName"] = "rabbitmq"
 
-		barbicanName := types.NamespacedName{
-			Namespace: namespace,
-			Name:      "barbican-webhook-test",
+		expectBarbicanUpdateRejected(spec, "barbican-webhook-test",
+			func(spec map[string]any) {
+				spec["rabbitMqClusterName"] = "rabbitmq2"
+			},
+			"field \"spec.rabbitMqClusterName\" is deprecated",
+			"use \"spec.messagingBus.cluster\" instead")
+	})
+	It("rejects kmip secret store without kmip specification", func() {
+		spec := GetDefaultBarbicanSpec()
+		spec["enabledSecretStores"] = []string{"kmip"}
+		spec["globalDefaultSecretStore"] = "kmip"
+
+		expectBarbicanRejected(spec, "barbican-kmip-webhook-test",
+			"spec.kmip: Required value")
+	})
+	It("rejects a pkcs11 mkek label without an hmac label", func() {
+		spec := GetPKCS11BarbicanSpec()
+		spec["pkcs11"] = map[string]any{
+			"loginSecret":      PKCS11LoginSecret,
+			"clientDataSecret": PKCS11ClientDataSecret,
+			"mkekLabel":        "barbican-mkek-1",
+		}
+
+		expectBarbicanRejected(spec, "barbican-pkcs11-webhook-test",
+			"mkekLabel and hmacLabel must be set together")
+	})
+	It("rejects unsupported pkcs11 settings", func() {
+		spec := GetTypedPKCS11BarbicanSpec()
+		pkcs11 := spec["pkcs11"].(map[string]any)
+		pkcs11["libraryPath"] = "libCryptoki2.so"
+		pkcs11["mkekLength"] = 20
+		pkcs11["encryptionMechanism"] = "CKM_DES3_CBC"
+		pkcs11["hmacMechanism"] = "sha256"
+
+		expectBarbicanRejected(spec, "barbican-pkcs11-settings-webhook-test",
+			"spec.PKCS11.libraryPath: Invalid value",
+			"spec.PKCS11.mkekLength: Unsupported value",
+			"spec.PKCS11.encryptionMechanism: Unsupported value",
+			"spec.PKCS11.hmacMechanism: Invalid value")
+	})
+	It("rejects a luna vendor without servers", func() {
+		spec := GetLunaBarbicanSpec()
+		delete(spec["pkcs11"].(map[string]any), "servers")
+
+		expectBarbicanRejected(spec, "barbican-luna-webhook-test",
+			"spec.PKCS11.servers: Required value")
+	})
+	It("rejects a softhsm vendor without an mkek label", func() {
+		spec := GetSoftHSMBarbicanSpec()
+		delete(spec["pkcs11"].(map[string]any), "mkekLabel")
+		delete(spec["pkcs11"].(map[string]any), "hmacLabel")
+
+		expectBarbicanRejected(spec, "barbican-softhsm-webhook-test",
+			"spec.PKCS11.mkekLabel: Required value")
+	})
+	It("rejects a secret store migration to a store that is not the global default", func() {
+		spec := GetSecretStoreMigrationBarbicanSpec()
+		spec["globalDefaultSecretStore"] = "simple_crypto"
+
+		expectBarbicanRejected(spec, "barbican-migration-webhook-test",
+			"spec.globalDefaultSecretStore: Invalid value")
+	})
+	It("rejects a project secret store that is not enabled", func() {
+		spec := GetProjectSecretStoresBarbicanSpec()
+		spec["projectSecretStores"] = map[string]any{
+			"project-a": "vault",
+		}
+
+		expectBarbicanRejected(spec, "barbican-project-stores-webhook-test",
+			"spec.projectSecretStores[project-a]: Invalid value")
+	})
+	It("rejects extraMounts mounting different volumes at the same path", func() {
+		spec := GetExtraMountsBarbicanSpec()
+		spec["extraMounts"] = append(spec["extraMounts"].([]map[string]any), map[string]any{
+			"propagation": []string{"BarbicanAPI"},
+			"volumes": []map[string]any{
+				{
+					"name":      "other-hsm-library",
+					"configMap": map[string]any{"name": "other-hsm-library"},
+				},
+			},
+			"mounts": []map[string]any{
+				{
+					"name":      "other-hsm-library",
+					"mountPath": "/usr/local/lib/hsm",
+					"readOnly":  true,
+				},
+			},
+		})
+
+		expectBarbicanRejected(spec, "other-hsm-library",
+			"different volumes are mounted at /usr/local/lib/hsm in the BarbicanAPI pods")
+	})
+	It("rejects a defaultConfigOverwrite of a file generated by the operator", func() {
+		spec := GetDefaultBarbicanSpec()
+		spec["barbicanAPI"] = map[string]any{
+			"defaultConfigOverwrite": map[string]any{
+				"httpd.conf":  "ServerRoot /tmp",
+				"policy.yaml": "{}",
+			},
 		}
 
-		raw := map[string]any{
-			"apiVersion": "barbican.openstack.org/v1beta1",
-			"kind":       "Barbican",
-			"metadata": map[string]any{
-				"name":      barbicanName.Name,
-				"namespace": barbicanName.Namespace,
+		expectBarbicanRejected(spec, "barbican-config-overwrite-webhook-test",
+			"spec.barbicanAPI.defaultConfigOverwrite[httpd.conf]: Forbidden",
+			"policy.yaml")
+	})
+	It("rejects a log module level that is not a Python module", func() {
+		spec := GetLoggingBarbicanSpec()
+		spec["barbicanWorker"] = map[string]any{
+			"logging": map[string]any{
+				"moduleLevels": map[string]any{
+					"barbican]\nkeys": "DEBUG",
+				},
 			},
-			"spec": spec,
 		}
 
-		// Create the Barbican instance
-		unstructuredObj := &unstructured.Unstructured{Object: raw}
-		_, err := controllerutil.CreateOrPatch(
-			ctx, k8sClient, unstructuredObj, func() error { return nil })
-		Expect(err).ShouldNot(HaveOccurred())
+		expectBarbicanRejected(spec, "barbican-logging-webhook-test",
+			"spec.barbicanWorker.logging.moduleLevels")
+	})
+	It("rejects an autoscaling without the resource request of its target", func() {
+		spec := GetDefaultBarbicanSpec()
+		spec["barbicanAPI"] = map[string]any{
+			"autoscaling": map[string]any{
+				"minReplicas": 3,
+				"maxReplicas": 2,
+			},
+		}
 
-		DeferCleanup(func() {
-			_ = k8sClient.Delete(ctx, unstructuredObj)
-		})
+		expectBarbicanRejected(spec, "barbican-autoscaling-webhook-test",
+			"spec.barbicanAPI.autoscaling.minReplicas: Invalid value",
+			"spec.barbicanAPI.autoscaling.targetCPUUtilizationPercentage: Required value")
+	})
+	It("rejects a PodDisruptionBudget with both minAvailable and maxUnavailable", func() {
+		spec := GetDefaultBarbicanSpec()
+		spec["barbicanKeystoneListener"] = map[string]any{
+			"podDisruptionBudget": map[string]any{
+				"minAvailable":   1,
+				"maxUnavailable": 1,
+			},
+		}
 
-		// Try to update rabbitMqClusterName
-		Eventually(func(g Gomega) {
-			g.Expect(k8sClient.Get(ctx, barbicanName, unstructuredObj)).Should(Succeed())
-			specMap := unstructuredObj.Object["spec"].(map[string]any)
-			specMap["rabbitMqClusterName"] = "rabbitmq2"
-			err := k8sClient.Update(ctx, unstructuredObj)
-			g.Expect(err).Should(HaveOccurred())
-
-			var statusError *k8s_errors.StatusError
-			g.Expect(errors.As(err, &statusError)).To(BeTrue())
-			g.Expect(statusError.ErrStatus.Details.Kind).To(Equal("Barbican"))
-			g.Expect(statusError.ErrStatus.Message).To(
-				ContainSubstring("field \"spec.rabbitMqClusterName\" is deprecated"))
-			g.Expect(statusError.ErrStatus.Message).To(
-				ContainSubstring("use \"spec.messagingBus.cluster\" instead"))
-		}, timeout, interval).Should(Succeed())
+		expectBarbicanRejected(spec, "barbican-pdb-webhook-test",
+			"spec.barbicanKeystoneListener.podDisruptionBudget.maxUnavailable: Forbidden")
+	})
+	It("rejects a rollout strategy that can not make progress", func() {
+		spec := GetDefaultBarbicanSpec()
+		spec["barbicanWorker"] = map[string]any{
+			"rolloutStrategy": map[string]any{
+				"maxSurge":                "0%",
+				"maxUnavailable":          0,
+				"minReadySeconds":         600,
+				"progressDeadlineSeconds": 300,
+			},
+		}
+
+		expectBarbicanRejected(spec, "barbican-rollout-webhook-test",
+			"spec.barbicanWorker.rolloutStrategy.maxUnavailable: Invalid value",
+			"spec.barbicanWorker.rolloutStrategy.progressDeadlineSeconds: Invalid value")
+	})
+	It("rejects vault secret store with an invalid url", func() {
+		spec := GetDefaultBarbicanSpec()
+		spec["enabledSecretStores"] = []string{"vault"}
+		spec["globalDefaultSecretStore"] = "vault"
+		spec["vault"] = map[string]any{
+			"url":        "vault.example.com",
+			"authSecret": VaultAuthSecret,
+		}
+
+		expectBarbicanRejected(spec, "barbican-vault-webhook-test",
+			"spec.vault.url: Invalid value")
+	})
+	It("rejects a db backup to both a volume and an object store", func() {
+		spec := GetDBBackupBarbicanSpec()
+		spec["dbBackup"].(map[string]any)["objectStoreSecret"] = "barbican-backup-s3"
+
+		expectBarbicanRejected(spec, "barbican-db-backup-webhook-test",
+			"spec.dbBackup.objectStoreSecret: Forbidden")
+	})
+	It("rejects a postgresql database without externalDatabase", func() {
+		spec := GetPostgreSQLBarbicanSpec()
+		delete(spec, "externalDatabase")
+
+		expectBarbicanRejected(spec, "barbican-postgresql-webhook-test",
+			"spec.externalDatabase: Required value")
+	})
+	It("rejects an update of the database type", func() {
+		expectBarbicanUpdateRejected(GetDefaultBarbicanSpec(), "barbican-database-type-webhook-test",
+			func(spec map[string]any) {
+				maps.Copy(spec, GetPostgreSQLBarbicanSpec())
+				delete(spec, "databaseInstance")
+			},
+			"spec.databaseType: Forbidden")
 	})
 })
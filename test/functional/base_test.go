@@ -17,12 +17,17 @@ limitations under the License.
 package functional
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 
 	maps "golang.org/x/exp/maps"
 
 	. "github.com/onsi/gomega" //revive:disable:dot-imports
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,6 +50,54 @@ func CreateBarbicanSecret(namespace string, name string) *corev1.Secret {
 	)
 }
 
+// SimpleCryptoNewKEKSelector is the field holding the KEK used to test KEK rotation
+const SimpleCryptoNewKEKSelector = "BarbicanSimpleCryptoNewKEK"
+
+func GetSimpleCryptoRotationBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["passwordSelectors"] = map[string]any{
+		"service":                    "BarbicanPassword",
+		"simplecryptokek":            "BarbicanSimpleCryptoKEK",
+		"simplecryptoadditionalkeks": []string{SimpleCryptoNewKEKSelector},
+	}
+	return spec
+}
+
+func CreateSimpleCryptoRotationSecret(namespace string, name string) *corev1.Secret {
+	return th.CreateSecret(
+		types.NamespacedName{Namespace: namespace, Name: name},
+		map[string][]byte{
+			"AdminPassword":            []byte("12345678"),
+			"BarbicanPassword":         []byte("12345678"),
+			"KeystoneDatabasePassword": []byte("12345678"),
+			"BarbicanSimpleCryptoKEK":  []byte("sEFmdFjDUqRM2VemYslV5yGNWjokioJXsg8Nrlc3drU="),
+			SimpleCryptoNewKEKSelector: []byte("dGhpc2lzYW5ld2tla3RoaXNpc2FuZXdrZWt0aGlzaXM="),
+		},
+	)
+}
+
+// SimulateConfigRolledOut simulates the rollout of a new config of the Barbican
+// by the Deployments of its services, once they are patched with it
+func SimulateConfigRolledOut(deployments ...types.NamespacedName) {
+	for _, name := range deployments {
+		Eventually(func(g Gomega) {
+			deployment := th.GetDeployment(name)
+			g.Expect(deployment.Generation).Should(BeNumerically(">", deployment.Status.ObservedGeneration))
+		}, timeout, interval).Should(Succeed())
+		th.SimulateDeploymentReplicaReady(name)
+	}
+}
+
+// GeneratedKEKSecretName is the Simple Crypto backend Secret generated by the operator
+const GeneratedKEKSecretName = "barbican-generated-kek"
+
+func GetGeneratedKEKBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["simpleCryptoBackendSecret"] = GeneratedKEKSecretName
+	spec["generateSimpleCryptoKEK"] = true
+	return spec
+}
+
 func CreateCustomConfigSecret(namespace string, name string, contents map[string][]byte) *corev1.Secret {
 	return th.CreateSecret(
 		types.NamespacedName{Namespace: namespace, Name: name},
@@ -246,6 +299,375 @@ func GetPKCS11BarbicanSpec() map[string]any {
 	return spec
 }
 
+func GetExtraMountsBarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["extraMounts"] = []map[string]any{
+		{
+			"propagation": []string{"Barbican"},
+			"volumes": []map[string]any{
+				{
+					"name":      "hsm-library",
+					"configMap": map[string]any{"name": "hsm-library"},
+				},
+			},
+			"mounts": []map[string]any{
+				{
+					"name":      "hsm-library",
+					"mountPath": "/usr/local/lib/hsm",
+					"readOnly":  true,
+				},
+			},
+		},
+		{
+			"propagation": []string{"BarbicanAPI", "DBSync"},
+			"volumes": []map[string]any{
+				{
+					"name":   "api-policy",
+					"secret": map[string]any{"secretName": "api-policy"},
+				},
+			},
+			"mounts": []map[string]any{
+				{
+					"name":      "api-policy",
+					"mountPath": "/etc/barbican/policy.d",
+					"readOnly":  true,
+				},
+			},
+		},
+	}
+	return spec
+}
+
+func GetTypedPKCS11BarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["customServiceConfig"] = barbicanTest.BaseCustomServiceConfig
+	spec["pkcs11"] = map[string]any{
+		"clientDataPath":      PKCS11ClientDataPath,
+		"loginSecret":         PKCS11LoginSecret,
+		"clientDataSecret":    PKCS11ClientDataSecret,
+		"libraryPath":         "/usr/local/luna/libs/64/libCryptoki2.so",
+		"slotId":              0,
+		"mkekLabel":           "barbican-mkek",
+		"mkekLength":          32,
+		"hmacLabel":           "barbican-hmac",
+		"hmacLength":          32,
+		"encryptionMechanism": "CKM_AES_GCM",
+		"hmacKeyType":         "CKK_GENERIC_SECRET",
+		"hmacKeygenMechanism": "CKM_GENERIC_SECRET_KEY_GEN",
+		"hmacMechanism":       "CKM_SHA256_HMAC",
+		"keyWrapMechanism":    "CKM_AES_CBC_PAD",
+		"aesGCMGenerateIV":    true,
+		"osLockingOK":         false,
+	}
+	return spec
+}
+
+func GetLunaBarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["customServiceConfig"] = barbicanTest.BaseCustomServiceConfig
+	spec["pkcs11"] = map[string]any{
+		"clientDataPath":   "/usr/local/luna",
+		"loginSecret":      PKCS11LoginSecret,
+		"clientDataSecret": PKCS11ClientDataSecret,
+		"vendor":           "luna",
+		"servers":          []string{"hsm1.example.com"},
+		"mkekLabel":        "barbican-mkek",
+		"hmacLabel":        "barbican-hmac",
+	}
+	return spec
+}
+
+func GetSoftHSMBarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["pkcs11"] = map[string]any{
+		"loginSecret": PKCS11LoginSecret,
+		"vendor":      "softhsm",
+		"mkekLabel":   "barbican-mkek",
+		"hmacLabel":   "barbican-hmac",
+	}
+	return spec
+}
+
+func GetSecretStoreMigrationBarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["enabledSecretStores"] = []string{"simple_crypto", "pkcs11"}
+	spec["globalDefaultSecretStore"] = "pkcs11"
+	spec["secretStoreMigration"] = map[string]any{
+		"source": "simple_crypto",
+		"target": "pkcs11",
+	}
+	return spec
+}
+
+func GetLoggingBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["logging"] = map[string]any{
+		"level": "DEBUG",
+		"moduleLevels": map[string]any{
+			"barbican.plugin": "DEBUG",
+			"amqp":            "ERROR",
+		},
+	}
+	spec["barbicanWorker"] = map[string]any{
+		"logging": map[string]any{
+			"level": "WARNING",
+			"json":  true,
+		},
+	}
+	return spec
+}
+
+func GetStdoutLoggingBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["logging"] = map[string]any{
+		"mode": "stdout",
+	}
+	// the keystone listener keeps logging to a file
+	spec["barbicanKeystoneListener"] = map[string]any{
+		"logging": map[string]any{
+			"mode": "file",
+		},
+	}
+	return spec
+}
+
+func GetWSGIBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["barbicanAPI"] = map[string]any{
+		"resources": map[string]any{
+			"requests": map[string]any{
+				"cpu": "5500m",
+			},
+		},
+		"wsgi": map[string]any{
+			"threads":         2,
+			"maximumRequests": 1000,
+		},
+	}
+	return spec
+}
+
+func GetAutoscalingBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["barbicanAPI"] = map[string]any{
+		"resources": map[string]any{
+			"requests": map[string]any{
+				"cpu": "500m",
+			},
+		},
+		"autoscaling": map[string]any{
+			"minReplicas":                    2,
+			"maxReplicas":                    5,
+			"targetCPUUtilizationPercentage": 60,
+		},
+	}
+	spec["barbicanWorker"] = map[string]any{
+		"resources": map[string]any{
+			"requests": map[string]any{
+				"memory": "256Mi",
+			},
+		},
+		"autoscaling": map[string]any{
+			"maxReplicas":                       3,
+			"targetMemoryUtilizationPercentage": 75,
+		},
+	}
+	return spec
+}
+
+func GetPodDisruptionBudgetBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["barbicanAPI"] = map[string]any{
+		"replicas": 3,
+	}
+	spec["barbicanWorker"] = map[string]any{
+		"replicas": 3,
+		"podDisruptionBudget": map[string]any{
+			"maxUnavailable": "50%",
+		},
+	}
+	return spec
+}
+
+func GetProbesBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["barbicanAPI"] = map[string]any{
+		"probes": map[string]any{
+			"livenessProbe": map[string]any{
+				"periodSeconds": 30,
+			},
+			"startupProbe": map[string]any{
+				"failureThreshold": 120,
+			},
+		},
+	}
+	return spec
+}
+
+func GetRolloutStrategyBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["barbicanAPI"] = map[string]any{
+		"rolloutStrategy": map[string]any{
+			"maxSurge":                1,
+			"maxUnavailable":          0,
+			"minReadySeconds":         10,
+			"progressDeadlineSeconds": 300,
+		},
+	}
+	spec["barbicanWorker"] = map[string]any{
+		"rolloutStrategy": map[string]any{
+			"paused": true,
+		},
+	}
+	return spec
+}
+
+func GetDBPurgeBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["dbPurge"] = map[string]any{
+		"schedule":                 "0 1 * * *",
+		"age":                      10,
+		"softDeleteExpiredSecrets": true,
+	}
+	return spec
+}
+
+func GetDBBackupBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["dbBackup"] = map[string]any{
+		"persistentVolumeClaim": "barbican-backup",
+		"retention":             2,
+	}
+	return spec
+}
+
+// ExternalDatabaseSecretName is the Secret holding the credentials of the external PostgreSQL DB
+const ExternalDatabaseSecretName = "barbican-postgresql"
+
+func GetPostgreSQLBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	delete(spec, "databaseInstance")
+	spec["databaseType"] = "postgresql"
+	spec["externalDatabase"] = map[string]any{
+		"host":    "postgresql.example.com",
+		"name":    "barbican_region1",
+		"secret":  ExternalDatabaseSecretName,
+		"sslMode": "require",
+	}
+	return spec
+}
+
+func CreateExternalDatabaseSecret(namespace string, name string) *corev1.Secret {
+	return th.CreateSecret(
+		types.NamespacedName{Namespace: namespace, Name: name},
+		map[string][]byte{
+			"username": []byte("barbican"),
+			"password": []byte("p@ss/wo$1rd"),
+		},
+	)
+}
+
+// RestoredKEKSecretName is the Simple Crypto backend Secret recreated by a BarbicanRestore
+const RestoredKEKSecretName = "barbican-restored-kek"
+
+func GetRestoreBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	spec["simpleCryptoBackendSecret"] = RestoredKEKSecretName
+	return spec
+}
+
+// CreateBackupKeySecret creates a Secret holding an RSA key pair in the
+// fields read by BarbicanBackup and BarbicanRestore
+func CreateBackupKeySecret(name types.NamespacedName) *corev1.Secret {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+	return th.CreateSecret(
+		name,
+		map[string][]byte{
+			barbicanv1.BackupPublicKeySelector: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}),
+			barbicanv1.RestorePrivateKeySelector: pem.EncodeToMemory(&pem.Block{
+				Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		},
+	)
+}
+
+func CreateBarbicanBackup(name types.NamespacedName, spec map[string]any) client.Object {
+	raw := map[string]any{
+		"apiVersion": "barbican.openstack.org/v1beta1",
+		"kind":       "BarbicanBackup",
+		"metadata": map[string]any{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetBarbicanBackup(name types.NamespacedName) *barbicanv1.BarbicanBackup {
+	instance := &barbicanv1.BarbicanBackup{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func BarbicanBackupConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetBarbicanBackup(name)
+	return instance.Status.Conditions
+}
+
+func CreateBarbicanRestore(name types.NamespacedName, spec map[string]any) client.Object {
+	raw := map[string]any{
+		"apiVersion": "barbican.openstack.org/v1beta1",
+		"kind":       "BarbicanRestore",
+		"metadata": map[string]any{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetBarbicanRestore(name types.NamespacedName) *barbicanv1.BarbicanRestore {
+	instance := &barbicanv1.BarbicanRestore{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func BarbicanRestoreConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetBarbicanRestore(name)
+	return instance.Status.Conditions
+}
+
+func GetProjectSecretStoresBarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["enabledSecretStores"] = []string{"simple_crypto", "pkcs11"}
+	spec["globalDefaultSecretStore"] = "simple_crypto"
+	spec["projectSecretStores"] = map[string]any{
+		"project-a": "pkcs11",
+		"project-b": "simple_crypto",
+	}
+	return spec
+}
+
+func GetPKCS11RotationBarbicanSpec() map[string]any {
+	spec := GetPKCS11BarbicanSpec()
+	spec["pkcs11"] = map[string]any{
+		"clientDataPath":   PKCS11ClientDataPath,
+		"loginSecret":      PKCS11LoginSecret,
+		"clientDataSecret": PKCS11ClientDataSecret,
+		"mkekLabel":        "barbican-mkek-1",
+		"hmacLabel":        "barbican-hmac-1",
+	}
+	return spec
+}
+
 func GetPKCS11BarbicanAPISpec() map[string]any {
 	spec := GetPKCS11BarbicanSpec()
 	maps.Copy(spec, GetDefaultBarbicanAPISpec())
@@ -276,6 +698,90 @@ func CreatePKCS11ClientDataSecret(namespace string, name string) *corev1.Secret
 
 // ========== End of PKCS11 Stuff ============
 
+// ========== KMIP Stuff ============
+func GetKMIPBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	maps.Copy(spec, map[string]any{
+		"enabledSecretStores":      []string{"kmip"},
+		"globalDefaultSecretStore": "kmip",
+		"kmip": map[string]any{
+			"host":             KMIPHost,
+			"clientCertSecret": KMIPClientCertSecret,
+			"caSecret":         KMIPCASecret,
+		},
+	})
+	return spec
+}
+
+func GetKMIPBarbicanAPISpec() map[string]any {
+	spec := GetKMIPBarbicanSpec()
+	maps.Copy(spec, GetDefaultBarbicanAPISpec())
+	return spec
+}
+
+func CreateKMIPClientCertSecret(namespace string, name string) *corev1.Secret {
+	return th.CreateSecret(
+		types.NamespacedName{Namespace: namespace, Name: name},
+		map[string][]byte{
+			"tls.crt": []byte("dummy-data"),
+			"tls.key": []byte("dummy-data"),
+		},
+	)
+}
+
+func CreateKMIPCASecret(namespace string, name string) *corev1.Secret {
+	return th.CreateSecret(
+		types.NamespacedName{Namespace: namespace, Name: name},
+		map[string][]byte{
+			"ca.crt": []byte("dummy-data"),
+		},
+	)
+}
+
+// ========== End of KMIP Stuff ============
+
+// ========== Vault Stuff ============
+func GetVaultBarbicanSpec() map[string]any {
+	spec := GetDefaultBarbicanSpec()
+	maps.Copy(spec, map[string]any{
+		"enabledSecretStores":      []string{"vault"},
+		"globalDefaultSecretStore": "vault",
+		"vault": map[string]any{
+			"url":        VaultURL,
+			"authSecret": VaultAuthSecret,
+			"caSecret":   VaultCASecret,
+			"namespace":  "barbican",
+		},
+	})
+	return spec
+}
+
+func GetVaultBarbicanAPISpec() map[string]any {
+	spec := GetVaultBarbicanSpec()
+	maps.Copy(spec, GetDefaultBarbicanAPISpec())
+	return spec
+}
+
+func CreateVaultAuthSecret(namespace string, name string) *corev1.Secret {
+	return th.CreateSecret(
+		types.NamespacedName{Namespace: namespace, Name: name},
+		map[string][]byte{
+			"token": []byte("vault-root-token"),
+		},
+	)
+}
+
+func CreateVaultCASecret(namespace string, name string) *corev1.Secret {
+	return th.CreateSecret(
+		types.NamespacedName{Namespace: namespace, Name: name},
+		map[string][]byte{
+			"ca.crt": []byte("dummy-data"),
+		},
+	)
+}
+
+// ========== End of Vault Stuff ============
+
 func GetDefaultBarbicanAPISpec() map[string]any {
 	return map[string]any{
 		"secret":                    SecretName,
@@ -390,3 +896,40 @@ func CreateBarbicanInvalidSecret(namespace string, name string) *corev1.Secret {
 		},
 	)
 }
+
+// SimulateDBSyncPod stands in for the pod of the db-sync Job running the
+// image, terminated with the message
+func SimulateDBSyncPod(image string, message string) *corev1.Pod {
+	dbSyncJob := &batchv1.Job{}
+	Eventually(func(g Gomega) {
+		dbSyncJob = th.GetJob(barbicanTest.BarbicanDBSync)
+		g.Expect(dbSyncJob.Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+	}, timeout, interval).Should(Succeed())
+	return SimulateJobPodTerminated(dbSyncJob, message)
+}
+
+// SimulateJobPodTerminated stands in for the pod of the Job, its container
+// terminated with the message
+func SimulateJobPodTerminated(job *batchv1.Job, message string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: job.Name + "-",
+			Namespace:    job.Namespace,
+			Labels:       map[string]string{batchv1.ControllerUidLabel: string(job.UID)},
+		},
+		Spec: *job.Spec.Template.Spec.DeepCopy(),
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  pod.Spec.Containers[0].Name,
+		Image: pod.Spec.Containers[0].Image,
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				Message:    message,
+				FinishedAt: metav1.Now(),
+			},
+		},
+	}}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	return pod
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functional
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// secretStoreAPIStores are the secret stores registered in the stub, keyed by
+// their ID
+var secretStoreAPIStores = map[string]map[string]any{
+	"simple-crypto": {
+		"name":                "Software Only Crypto",
+		"secret_store_plugin": "store_crypto",
+		"crypto_plugin":       "simple_crypto",
+		"global_default":      true,
+	},
+	"pkcs11": {
+		"name":                "PKCS11 HSM",
+		"secret_store_plugin": "store_crypto",
+		"crypto_plugin":       "p11_crypto",
+		"global_default":      false,
+	},
+	"custom": {
+		"name":                "Custom Store",
+		"secret_store_plugin": "custom_plugin",
+		"crypto_plugin":       "",
+		"global_default":      false,
+	},
+}
+
+// SecretStoreAPIStub stands in for the Keystone token and the Barbican secret
+// store APIs used to manage the preferred secret store of projects. Tokens are
+// "token-<project ID>" so the project of a request comes from its token.
+type SecretStoreAPIStub struct {
+	Server *httptest.Server
+
+	mu        sync.Mutex
+	preferred map[string]string
+}
+
+// NewSecretStoreAPIStub starts a SecretStoreAPIStub
+func NewSecretStoreAPIStub() *SecretStoreAPIStub {
+	stub := &SecretStoreAPIStub{preferred: map[string]string{}}
+	stub.Server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	return stub
+}
+
+// Reset removes the preferred secret store of all the projects
+func (s *SecretStoreAPIStub) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preferred = map[string]string{}
+}
+
+// GetPreferred returns the ID of the preferred secret store of the project,
+// empty when the project uses the global default
+func (s *SecretStoreAPIStub) GetPreferred(project string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.preferred[project]
+}
+
+// SetPreferred sets the preferred secret store of the project behind the back
+// of the operator
+func (s *SecretStoreAPIStub) SetPreferred(project string, storeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preferred[project] = storeID
+}
+
+// Transport returns a RoundTripper sending every request to the stub,
+// whatever the Keystone and Barbican URLs are
+func (s *SecretStoreAPIStub) Transport() http.RoundTripper {
+	return secretStoreAPITransport{host: strings.TrimPrefix(s.Server.URL, "http://")}
+}
+
+type secretStoreAPITransport struct {
+	host string
+}
+
+func (t secretStoreAPITransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	req.URL.Host = t.host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *SecretStoreAPIStub) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/v3/auth/tokens" && req.Method == http.MethodPost {
+		auth := struct {
+			Auth struct {
+				Scope struct {
+					Project struct {
+						ID string `json:"id"`
+					} `json:"project"`
+				} `json:"scope"`
+			} `json:"auth"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&auth); err != nil || auth.Auth.Scope.Project.ID == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Subject-Token", "token-"+auth.Auth.Scope.Project.ID)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	project, found := strings.CutPrefix(req.Header.Get("X-Auth-Token"), "token-")
+	if !found {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v1/secret-stores")
+	switch {
+	case path == "" && req.Method == http.MethodGet:
+		stores := []map[string]any{}
+		for id := range secretStoreAPIStores {
+			stores = append(stores, s.store(req, id))
+		}
+		writeJSON(w, map[string]any{"secret_stores": stores})
+	case path == "/preferred" && req.Method == http.MethodGet:
+		id, ok := s.preferred[project]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, s.store(req, id))
+	case strings.HasSuffix(path, "/preferred"):
+		id := strings.Trim(strings.TrimSuffix(path, "/preferred"), "/")
+		if _, ok := secretStoreAPIStores[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch req.Method {
+		case http.MethodPost:
+			s.preferred[project] = id
+		case http.MethodDelete:
+			delete(s.preferred, project)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *SecretStoreAPIStub) store(req *http.Request, id string) map[string]any {
+	store := map[string]any{
+		"secret_store_ref": "http://" + req.Host + "/v1/secret-stores/" + id,
+	}
+	for k, v := range secretStoreAPIStores[id] {
+		store[k] = v
+	}
+	return store
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -63,6 +63,8 @@ var (
 	namespace    string
 	barbicanName types.NamespacedName
 	barbicanTest BarbicanTestData
+
+	secretStoreAPI *SecretStoreAPIStub
 )
 
 const (
@@ -198,10 +200,13 @@ var _ = BeforeSuite(func() {
 	kclient, err := kubernetes.NewForConfig(cfg)
 	Expect(err).ToNot(HaveOccurred(), "failed to create kclient")
 
+	secretStoreAPI = NewSecretStoreAPIStub()
+
 	err = (&controllers.BarbicanReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Kclient: kclient,
+		Client:                     k8sManager.GetClient(),
+		Scheme:                     k8sManager.GetScheme(),
+		Kclient:                    kclient,
+		SecretStoreClientTransport: secretStoreAPI.Transport(),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -226,6 +231,20 @@ var _ = BeforeSuite(func() {
 		Kclient: kclient,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
+
+	err = (&controllers.BarbicanBackupReconciler{
+		Client:  k8sManager.GetClient(),
+		Scheme:  k8sManager.GetScheme(),
+		Kclient: kclient,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&controllers.BarbicanRestoreReconciler{
+		Client:  k8sManager.GetClient(),
+		Scheme:  k8sManager.GetScheme(),
+		Kclient: kclient,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
 	barbicanv1.SetupDefaults()
 
 	err = barbicanwebhook.SetupBarbicanWebhookWithManager(k8sManager)
@@ -253,6 +272,7 @@ var _ = BeforeSuite(func() {
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cancel()
+	secretStoreAPI.Server.Close()
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })